@@ -3,32 +3,139 @@ package main
 import (
   "time"
   "encoding/json"
+  "flag"
   "net/http"
+  "fmt"
+  "os"
+  "context"
+  "errors"
+  "os/signal"
+  "syscall"
+
+  "servertime/timeservice"
 )
 
-type ServiceResult struct {
-  FormattedTime string
-  Greeting string
-}
+func main() {
+  printConfig := flag.Bool("print-config", false, "print the resolved configuration as JSON and exit")
+  flag.Parse()
 
-func currentTime() string {
-  return time.Now().Format(time.RFC822Z)
-}
+  cfg, err := timeservice.LoadConfig()
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  if *printConfig {
+    js, err := json.MarshalIndent(cfg, "", "  ")
+    if err != nil {
+      fmt.Fprintln(os.Stderr, err)
+      os.Exit(1)
+    }
+    fmt.Println(string(js))
+    return
+  }
+  logger, closeLogging, err := timeservice.SetupCloudLogging(context.Background(), cfg)
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  cfg.Logger = logger
 
-func handler(w http.ResponseWriter, r *http.Request) {
-  t := currentTime()
-  sr := ServiceResult{t, "Hi there"}
-  js, err := json.Marshal(sr)
+  if cfg.NTPServer != "" {
+    clock, err := timeservice.NewNTPClock(cfg.NTPServer)
+    if err != nil {
+      cfg.Logger.Warn("NTP unavailable, using the system clock", "err", err)
+    } else {
+      cfg.Logger.Info("using NTP clock", "server", cfg.NTPServer, "offset", clock.Offset())
+      cfg.Clock = clock
+    }
+  }
+  cfg.StartTime = cfg.Clock.Now()
+  if cfg.Chaos {
+    cfg.Logger.Warn("chaos mode enabled", "max_delay", cfg.ChaosMaxDelay, "error_percent", cfg.ChaosErrorPercent)
+  }
+  if !timeservice.TZDataAvailable() {
+    cfg.Logger.Warn("time zone database not found, named zones will be rejected; build with -tags timetzdata to embed it")
+  }
+  shutdownTracing, err := timeservice.SetupTracing(context.Background(), cfg)
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  if err := timeservice.SelfCheck(cfg); err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  server := timeservice.NewServer(cfg)
+  ln, err := timeservice.Listen(cfg)
+  if err != nil {
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  // The clock and tracing are settled, so /readyz can start passing.
+  cfg.State.SetReady(true)
+
+  serve := func() error { return server.Serve(ln) }
+  if cfg.TLSCert != "" {
+    serve = func() error { return server.ServeTLS(ln, cfg.TLSCert, cfg.TLSKey) }
+  }
+  err = run(server, serve, cfg)
 
+  ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+  defer cancel()
+  if err := shutdownTracing(ctx); err != nil {
+    cfg.Logger.Error("flushing traces failed", "err", err)
+  }
   if err != nil {
-    http.Error(w, err.Error(), http.StatusInternalServerError)
-    return
+    cfg.Logger.Error("server failed", "err", err)
+  }
+  // Flush logs last so the shutdown messages above are delivered.
+  if err := closeLogging(); err != nil {
+    fmt.Fprintln(os.Stderr, "flushing logs failed:", err)
+  }
+  if err != nil {
+    os.Exit(1)
   }
-  w.Header().Set("Content-Type", "application/json")
-  w.Write(js)
 }
 
-func main() {
-  http.HandleFunc("/", handler)
-  http.ListenAndServe(":80", nil)
+const shutdownTimeout = 10 * time.Second
+
+// run calls serve until the process receives SIGINT or SIGTERM, or
+// cfg.DrainGracePeriod after a drain begins, then gives in-flight
+// requests up to shutdownTimeout to complete.
+func run(server *http.Server, serve func() error, cfg timeservice.Config) error {
+  logger := cfg.Logger
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+  defer stop()
+
+  errc := make(chan error, 1)
+  go func() {
+    errc <- serve()
+  }()
+
+  select {
+  case err := <-errc:
+    return err
+  case <-ctx.Done():
+  case <-cfg.State.Draining():
+    // Keep serving while load balancers take us out of rotation; a
+    // signal cuts the wait short.
+    select {
+    case err := <-errc:
+      return err
+    case <-time.After(cfg.DrainGracePeriod):
+    case <-ctx.Done():
+    }
+  }
+
+  logger.Info("shutting down")
+  ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+  defer cancel()
+  if err := server.Shutdown(ctx); err != nil {
+    return err
+  }
+  if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+    return err
+  }
+  logger.Info("shutdown complete")
+  return nil
 }
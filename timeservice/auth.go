@@ -0,0 +1,38 @@
+package timeservice
+
+import (
+  "crypto/subtle"
+  "net/http"
+  "strings"
+)
+
+// presentedAPIKey returns the key from an "Authorization: Bearer" or
+// X-API-Key header.
+func presentedAPIKey(r *http.Request) string {
+  if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+    return strings.TrimSpace(token)
+  }
+  return r.Header.Get("X-API-Key")
+}
+
+// validAPIKey compares in constant time so response timing doesn't leak
+// how much of the key matched.
+func validAPIKey(r *http.Request, apiKey string) bool {
+  return subtle.ConstantTimeCompare([]byte(presentedAPIKey(r)), []byte(apiKey)) == 1
+}
+
+// requireAPIKey rejects requests without apiKey with 401 Unauthorized.
+// It does nothing when apiKey is empty, and never guards the probes.
+func requireAPIKey(apiKey string, next http.Handler) http.Handler {
+  if apiKey == "" {
+    return next
+  }
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if probePaths[r.URL.Path] || validAPIKey(r, apiKey) {
+      next.ServeHTTP(w, r)
+      return
+    }
+    w.Header().Set("WWW-Authenticate", "Bearer")
+    writeError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+  })
+}
@@ -0,0 +1,41 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+  next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+  tests := []struct {
+    name string
+    apiKey string
+    path string
+    header string
+    value string
+    status int
+  }{
+    {"disabled", "", "/", "", "", http.StatusOK},
+    {"bearer", "s3cret", "/", "Authorization", "Bearer s3cret", http.StatusOK},
+    {"x-api-key", "s3cret", "/", "X-API-Key", "s3cret", http.StatusOK},
+    {"missing", "s3cret", "/", "", "", http.StatusUnauthorized},
+    {"wrong bearer", "s3cret", "/", "Authorization", "Bearer s3cre", http.StatusUnauthorized},
+    {"wrong scheme", "s3cret", "/", "Authorization", "Basic s3cret", http.StatusUnauthorized},
+    {"healthz exempt", "s3cret", "/healthz", "", "", http.StatusOK},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest("GET", tt.path, nil)
+    if tt.header != "" {
+      req.Header.Set(tt.header, tt.value)
+    }
+    rec := httptest.NewRecorder()
+    requireAPIKey(tt.apiKey, next).ServeHTTP(rec, req)
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.status)
+    }
+    if tt.status == http.StatusUnauthorized && rec.Header().Get("Content-Type") != "application/json" {
+      t.Errorf("%s: expected a JSON error body", tt.name)
+    }
+  }
+}
@@ -0,0 +1,54 @@
+package timeservice
+
+import (
+  "errors"
+  "fmt"
+  "net/http"
+)
+
+const maxBatchZones = 50
+
+// BatchEntry is the result for one requested zone: its TimeInfo, or an
+// Error when the zone could not be loaded.
+type BatchEntry struct {
+  Zone string `json:"zone"`
+  *TimeInfo
+  Error string `json:"error,omitempty"`
+}
+
+// BatchHandler returns TimeInfo for each repeated tz parameter, in
+// request order and all for the same instant. An invalid zone fails only
+// its own entry.
+func BatchHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    zones := r.URL.Query()["tz"]
+    if len(zones) == 0 {
+      writeError(w, http.StatusBadRequest, "missing tz")
+      return
+    }
+    if len(zones) > maxBatchZones {
+      writeError(w, http.StatusBadRequest, fmt.Sprintf("too many tz values: at most %d are allowed", maxBatchZones))
+      return
+    }
+
+    now := cfg.Clock.Now()
+    entries := make([]BatchEntry, len(zones))
+    for i, zone := range zones {
+      entries[i].Zone = zone
+      loc, err := lookupLocation(zone)
+      if errors.Is(err, errNoTZData) {
+        entries[i].Error = err.Error()
+        continue
+      }
+      if err != nil || zone == "" {
+        entries[i].Error = fmt.Sprintf("unknown time zone %q", zone)
+        continue
+      }
+      info := newTimeInfo(now, loc)
+      entries[i].TimeInfo = &info
+    }
+    writeJSON(w, r, entries)
+  }
+}
@@ -0,0 +1,71 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestBatch(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+
+  rec := httptest.NewRecorder()
+  BatchHandler(cfg)(rec, httptest.NewRequest("GET", "/batch?tz=UTC&tz=Nowhere/Special&tz=Asia/Tokyo", nil))
+  if rec.Code != http.StatusOK {
+    t.Fatalf("got status %d", rec.Code)
+  }
+  var entries []BatchEntry
+  if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+    t.Fatal(err)
+  }
+  if len(entries) != 3 {
+    t.Fatalf("got %d entries, want 3", len(entries))
+  }
+  tests := []struct {
+    zone string
+    offset int
+    fail bool
+  }{
+    {"UTC", 0, false},
+    {"Nowhere/Special", 0, true},
+    {"Asia/Tokyo", 9 * 3600, false},
+  }
+  for i, tt := range tests {
+    e := entries[i]
+    if e.Zone != tt.zone {
+      t.Errorf("entry %d: got zone %q, want %q", i, e.Zone, tt.zone)
+    }
+    if tt.fail {
+      if e.Error == "" || e.TimeInfo != nil {
+        t.Errorf("entry %d: expected only an error, got %+v", i, e)
+      }
+      continue
+    }
+    if e.Error != "" || e.TimeInfo == nil || e.Offset != tt.offset || e.Unix != 1474627140 {
+      t.Errorf("entry %d: unexpected %+v", i, e)
+    }
+  }
+}
+
+func TestBatchLimits(t *testing.T) {
+  tests := []struct {
+    name string
+    query string
+    status int
+  }{
+    {"none", "", http.StatusBadRequest},
+    {"at limit", "?" + strings.Repeat("tz=UTC&", maxBatchZones), http.StatusOK},
+    {"over limit", "?" + strings.Repeat("tz=UTC&", maxBatchZones+1), http.StatusBadRequest},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    BatchHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/batch"+tt.query, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.status)
+    }
+  }
+}
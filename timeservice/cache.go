@@ -0,0 +1,111 @@
+package timeservice
+
+import (
+  "bytes"
+  "fmt"
+  "math"
+  "net/http"
+  "sync"
+  "time"
+)
+
+// maxCacheEntries bounds the cache, since keys include client-chosen
+// query parameters.
+const maxCacheEntries = 1024
+
+type cacheEntry struct {
+  contentType string
+  body []byte
+  expires time.Time
+}
+
+// responseCache memoizes successful responses of endpoints whose output
+// depends only on their parameters, for ttl. Expired entries are swept
+// from the request path at most once per ttl, like rateLimiter's.
+type responseCache struct {
+  ttl time.Duration
+
+  mu sync.Mutex
+  entries map[string]cacheEntry
+  lastSweep time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+  return &responseCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *responseCache) get(key string, now time.Time) (cacheEntry, bool) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  e, ok := c.entries[key]
+  if !ok || !now.Before(e.expires) {
+    return cacheEntry{}, false
+  }
+  return e, true
+}
+
+// put stores e under key, unless the cache is still full after sweeping.
+func (c *responseCache) put(key string, e cacheEntry, now time.Time) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  if now.Sub(c.lastSweep) >= c.ttl || len(c.entries) >= maxCacheEntries {
+    for k, old := range c.entries {
+      if !now.Before(old.expires) {
+        delete(c.entries, k)
+      }
+    }
+    c.lastSweep = now
+  }
+  if len(c.entries) < maxCacheEntries {
+    c.entries[key] = e
+  }
+}
+
+// bufferedResponse captures a response so it can be cached.
+type bufferedResponse struct {
+  header http.Header
+  status int
+  body bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// middleware serves next's 200 responses from the cache, keyed by path
+// and query, with a max-age matching what is left of the entry's TTL.
+// Enveloped responses carry per-request metadata and are never cached.
+func (c *responseCache) middleware(next http.Handler) http.Handler {
+  if c.ttl <= 0 {
+    return next
+  }
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if _, ok := envelopeClock(r.Context()); ok {
+      next.ServeHTTP(w, r)
+      return
+    }
+    now := time.Now()
+    key := r.URL.Path + "?" + r.URL.Query().Encode()
+    e, ok := c.get(key, now)
+    if !ok {
+      // Render the full body even if the client could take a 304.
+      inner := r.Clone(r.Context())
+      inner.Header.Del("If-None-Match")
+      rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+      next.ServeHTTP(rec, inner)
+      if rec.status != http.StatusOK {
+        for k, v := range rec.header {
+          w.Header()[k] = v
+        }
+        w.WriteHeader(rec.status)
+        w.Write(rec.body.Bytes())
+        return
+      }
+      e = cacheEntry{contentType: rec.header.Get("Content-Type"), body: rec.body.Bytes(), expires: now.Add(c.ttl)}
+      c.put(key, e, now)
+    }
+    maxAge := int(math.Ceil(e.expires.Sub(now).Seconds()))
+    w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", maxAge))
+    writeCacheable(w, r, e.contentType, e.body)
+  })
+}
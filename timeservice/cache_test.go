@@ -0,0 +1,85 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestResponseCache(t *testing.T) {
+  c := newResponseCache(time.Minute)
+  now := time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC)
+  if _, ok := c.get("k", now); ok {
+    t.Fatal("hit on an empty cache")
+  }
+  c.put("k", cacheEntry{body: []byte("v"), expires: now.Add(c.ttl)}, now)
+  if e, ok := c.get("k", now.Add(30*time.Second)); !ok || string(e.body) != "v" {
+    t.Errorf("expected a hit before expiry, got %q, %v", e.body, ok)
+  }
+  if _, ok := c.get("k", now.Add(time.Minute)); ok {
+    t.Error("hit after expiry")
+  }
+  c.put("other", cacheEntry{expires: now.Add(2 * time.Minute)}, now.Add(time.Minute))
+  if _, ok := c.entries["k"]; ok {
+    t.Error("expired entry was not swept")
+  }
+}
+
+func TestResponseCacheMiddleware(t *testing.T) {
+  calls := 0
+  h := newResponseCache(time.Minute).middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    calls++
+    if r.URL.Query().Get("fail") != "" {
+      writeError(w, http.StatusBadRequest, "bad")
+      return
+    }
+    writeJSON(w, r, DurationResult{1, "1s"})
+  }))
+  get := func(target string) *httptest.ResponseRecorder {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+    return rec
+  }
+
+  first := get("/v1/duration?a=1&b=2")
+  second := get("/v1/duration?b=2&a=1")
+  if calls != 1 {
+    t.Errorf("handler ran %d times, want 1", calls)
+  }
+  if first.Body.String() != second.Body.String() {
+    t.Errorf("cached body %q differs from %q", second.Body.String(), first.Body.String())
+  }
+  if got := second.Header().Get("Cache-Control"); got != "max-age=60" {
+    t.Errorf("got Cache-Control %q", got)
+  }
+  if second.Header().Get("ETag") == "" {
+    t.Error("missing ETag")
+  }
+
+  get("/v1/duration?a=2")
+  if calls != 2 {
+    t.Errorf("different parameters: handler ran %d times, want 2", calls)
+  }
+
+  get("/v1/duration?fail=1")
+  if rec := get("/v1/duration?fail=1"); rec.Code != http.StatusBadRequest || calls != 4 {
+    t.Errorf("errors should not be cached: got status %d after %d calls", rec.Code, calls)
+  }
+}
+
+func TestLiveEndpointsUncached(t *testing.T) {
+  h := NewHandler(testConfig(t))
+  for _, path := range []string{"/", "/v1/time", "/v1/now"} {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+    if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+      t.Errorf("%s: got Cache-Control %q", path, got)
+    }
+  }
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/version", nil))
+  if got := rec.Header().Get("Cache-Control"); got != "max-age=300" {
+    t.Errorf("/version: got Cache-Control %q", got)
+  }
+}
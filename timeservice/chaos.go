@@ -0,0 +1,70 @@
+package timeservice
+
+import (
+  "math/rand/v2"
+  "net/http"
+  "sync"
+  "time"
+)
+
+// chaosPaths are the routes served by the root handler, the only ones
+// chaos mode touches.
+var chaosPaths = map[string]bool{
+  "/": true,
+  "/v1/time": true,
+}
+
+// chaos delays and fails requests at random, as configured by
+// Config.Chaos.
+type chaos struct {
+  maxDelay time.Duration
+  errorPercent float64
+
+  mu sync.Mutex
+  rand *rand.Rand
+}
+
+func newChaos(cfg Config) *chaos {
+  r := cfg.ChaosRand
+  if r == nil {
+    now := uint64(time.Now().UnixNano())
+    r = rand.New(rand.NewPCG(now, now>>32))
+  }
+  return &chaos{maxDelay: cfg.ChaosMaxDelay, errorPercent: cfg.ChaosErrorPercent, rand: r}
+}
+
+// roll draws the delay and outcome for one request. rand.Rand is not
+// safe for concurrent use, hence the lock.
+func (c *chaos) roll() (time.Duration, bool) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  var delay time.Duration
+  if c.maxDelay > 0 {
+    delay = time.Duration(c.rand.Int64N(int64(c.maxDelay) + 1))
+  }
+  return delay, c.rand.Float64()*100 < c.errorPercent
+}
+
+// middleware applies chaos to requests for chaosPaths. The delay ends
+// early if the client goes away.
+func (c *chaos) middleware(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if !chaosPaths[r.URL.Path] {
+      next.ServeHTTP(w, r)
+      return
+    }
+    delay, fail := c.roll()
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+    case <-r.Context().Done():
+      return
+    }
+    if fail {
+      writeError(w, http.StatusInternalServerError, "chaos: injected failure")
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
@@ -0,0 +1,58 @@
+package timeservice
+
+import (
+  "math/rand/v2"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestChaosDisabled(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.ChaosErrorPercent = 100
+  h := NewHandler(cfg)
+  for i := 0; i < 10; i++ {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+    if rec.Code != http.StatusOK {
+      t.Fatalf("got status %d with chaos disabled", rec.Code)
+    }
+  }
+}
+
+func TestChaos(t *testing.T) {
+  const seed = 42
+  cfg := testConfig(t)
+  cfg.Chaos = true
+  cfg.ChaosMaxDelay = time.Millisecond
+  cfg.ChaosErrorPercent = 50
+  cfg.ChaosRand = rand.New(rand.NewPCG(seed, seed))
+  h := NewHandler(cfg)
+
+  // Replay the same draws to know which requests should fail.
+  want := &chaos{maxDelay: cfg.ChaosMaxDelay, errorPercent: cfg.ChaosErrorPercent, rand: rand.New(rand.NewPCG(seed, seed))}
+  failures := 0
+  for i := 0; i < 20; i++ {
+    _, fail := want.roll()
+    wantStatus := http.StatusOK
+    if fail {
+      wantStatus = http.StatusInternalServerError
+      failures++
+    }
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+    if rec.Code != wantStatus {
+      t.Errorf("request %d: got status %d, want %d", i, rec.Code, wantStatus)
+    }
+  }
+  if failures == 0 || failures == 20 {
+    t.Errorf("seed %d gave %d failures out of 20, expected a mix", seed, failures)
+  }
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+  if rec.Code != http.StatusOK {
+    t.Errorf("/healthz: got status %d, want %d", rec.Code, http.StatusOK)
+  }
+}
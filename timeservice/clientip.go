@@ -0,0 +1,52 @@
+package timeservice
+
+import (
+  "context"
+  "net"
+  "net/http"
+  "strings"
+)
+
+type clientIPKey struct{}
+
+// resolveClientIP returns the address of the client that sent r. When
+// trustProxy is set it prefers the left-most X-Forwarded-For entry, then
+// X-Real-IP; otherwise, or when neither is present, it uses the
+// RemoteAddr host. The headers are trivially spoofed, so they must only
+// be trusted behind a proxy that sets them.
+func resolveClientIP(r *http.Request, trustProxy bool) string {
+  if trustProxy {
+    if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+      first, _, _ := strings.Cut(xff, ",")
+      if ip := strings.TrimSpace(first); ip != "" {
+        return ip
+      }
+    }
+    if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+      return ip
+    }
+  }
+  host, _, err := net.SplitHostPort(r.RemoteAddr)
+  if err != nil {
+    return r.RemoteAddr
+  }
+  return host
+}
+
+// withClientIP resolves the client address once per request for
+// clientIP.
+func withClientIP(trustProxy bool, next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ctx := context.WithValue(r.Context(), clientIPKey{}, resolveClientIP(r, trustProxy))
+    next.ServeHTTP(w, r.WithContext(ctx))
+  })
+}
+
+// clientIP returns the client address resolved by withClientIP, falling
+// back to the untrusted RemoteAddr host outside that middleware.
+func clientIP(r *http.Request) string {
+  if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+    return ip
+  }
+  return resolveClientIP(r, false)
+}
@@ -0,0 +1,53 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+  tests := []struct {
+    name string
+    trust bool
+    xff string
+    realIP string
+    want string
+  }{
+    {"remote addr", true, "", "", "192.0.2.1"},
+    {"forwarded for", true, "203.0.113.7, 198.51.100.2", "198.51.100.9", "203.0.113.7"},
+    {"real ip", true, "", "198.51.100.9", "198.51.100.9"},
+    {"empty forwarded entry", true, " , 198.51.100.2", "198.51.100.9", "198.51.100.9"},
+    {"untrusted forwarded for", false, "203.0.113.7", "", "192.0.2.1"},
+    {"untrusted real ip", false, "", "198.51.100.9", "192.0.2.1"},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest("GET", "/", nil)
+    req.RemoteAddr = "192.0.2.1:4321"
+    if tt.xff != "" {
+      req.Header.Set("X-Forwarded-For", tt.xff)
+    }
+    if tt.realIP != "" {
+      req.Header.Set("X-Real-IP", tt.realIP)
+    }
+    if got := resolveClientIP(req, tt.trust); got != tt.want {
+      t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+    }
+  }
+}
+
+func TestClientIP(t *testing.T) {
+  var got string
+  h := withClientIP(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    got = clientIP(r)
+  }))
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("X-Forwarded-For", "203.0.113.7")
+  h.ServeHTTP(httptest.NewRecorder(), req)
+  if got != "203.0.113.7" {
+    t.Errorf("got %q, want 203.0.113.7", got)
+  }
+  if ip := clientIP(req); ip != "192.0.2.1" {
+    t.Errorf("outside the middleware got %q, want the RemoteAddr host", ip)
+  }
+}
@@ -0,0 +1,17 @@
+package timeservice
+
+import (
+  "time"
+)
+
+// Clock is the source of the current time for handlers, so tests can
+// substitute a fixed one.
+type Clock interface {
+  Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+  return time.Now()
+}
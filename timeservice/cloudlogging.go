@@ -0,0 +1,178 @@
+package timeservice
+
+import (
+  "context"
+  "fmt"
+  "log/slog"
+  "os"
+  "strings"
+  "time"
+
+  "cloud.google.com/go/logging"
+)
+
+const cloudLogID = "servertime"
+
+// cloudLabels are attributes promoted to Cloud Logging labels, which
+// can be indexed and filtered on, as well as kept in the payload.
+var cloudLabels = map[string]bool{
+  "path": true,
+  "status": true,
+}
+
+// cloudHandler is an slog.Handler that sends each record to Cloud
+// Logging as a structured entry. Groups are flattened into dotted keys.
+type cloudHandler struct {
+  log func(logging.Entry)
+  level slog.Leveler
+  attrs []slog.Attr // each a group named by the prefix it was added under
+  prefix string
+}
+
+func (h *cloudHandler) Enabled(_ context.Context, level slog.Level) bool {
+  return level >= h.level.Level()
+}
+
+func (h *cloudHandler) Handle(_ context.Context, rec slog.Record) error {
+  payload := map[string]any{"message": rec.Message}
+  labels := map[string]string{}
+  for _, a := range h.attrs {
+    addCloudAttr(payload, labels, "", a)
+  }
+  rec.Attrs(func(a slog.Attr) bool {
+    addCloudAttr(payload, labels, h.prefix, a)
+    return true
+  })
+
+  h.log(logging.Entry{
+    Timestamp: rec.Time,
+    Severity: cloudSeverity(requestLevel(rec)),
+    Payload: payload,
+    Labels: labels,
+  })
+  return nil
+}
+
+// addCloudAttr adds a to payload under prefix, flattening groups into
+// dotted keys and inlining those without a key. Empty attributes are
+// dropped, as slog.Handler requires.
+func addCloudAttr(payload map[string]any, labels map[string]string, prefix string, a slog.Attr) {
+  a.Value = a.Value.Resolve()
+  if a.Equal(slog.Attr{}) {
+    return
+  }
+  if a.Value.Kind() == slog.KindGroup {
+    if a.Key != "" {
+      prefix += a.Key + "."
+    }
+    for _, ga := range a.Value.Group() {
+      addCloudAttr(payload, labels, prefix, ga)
+    }
+    return
+  }
+  key := prefix + a.Key
+  payload[key] = cloudValue(a.Value)
+  if cloudLabels[key] {
+    labels[key] = a.Value.String()
+  }
+}
+
+// cloudValue converts a resolved, non-group value to one that encodes
+// as a JSON scalar. Errors and other arbitrary values, which would
+// otherwise marshal as {} or fail, are sent as their text.
+func cloudValue(v slog.Value) any {
+  switch v.Kind() {
+  case slog.KindDuration:
+    return v.Duration().String()
+  case slog.KindTime:
+    return v.Time().Format(time.RFC3339Nano)
+  case slog.KindAny:
+    if err, ok := v.Any().(error); ok {
+      return err.Error()
+    }
+    return fmt.Sprint(v.Any())
+  default:
+    return v.Any()
+  }
+}
+
+func (h *cloudHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+  if len(attrs) == 0 {
+    return h
+  }
+  h2 := *h
+  group := slog.Attr{Key: strings.TrimSuffix(h.prefix, "."), Value: slog.GroupValue(attrs...)}
+  h2.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], group)
+  return &h2
+}
+
+func (h *cloudHandler) WithGroup(name string) slog.Handler {
+  if name == "" {
+    return h
+  }
+  h2 := *h
+  h2.prefix = h.prefix + name + "."
+  return &h2
+}
+
+// requestLevel is the level of rec, raised for the request lines
+// logRequests writes at Info when their status shows the request failed:
+// 5xx to Error and 4xx to Warn.
+func requestLevel(rec slog.Record) slog.Level {
+  level := rec.Level
+  if rec.Message != "request" {
+    return level
+  }
+  rec.Attrs(func(a slog.Attr) bool {
+    if a.Key != "status" || a.Value.Kind() != slog.KindInt64 {
+      return true
+    }
+    switch status := a.Value.Int64(); {
+    case status >= 500:
+      level = max(level, slog.LevelError)
+    case status >= 400:
+      level = max(level, slog.LevelWarn)
+    }
+    return false
+  })
+  return level
+}
+
+func cloudSeverity(level slog.Level) logging.Severity {
+  switch {
+  case level >= slog.LevelError:
+    return logging.Error
+  case level >= slog.LevelWarn:
+    return logging.Warning
+  case level >= slog.LevelInfo:
+    return logging.Info
+  default:
+    return logging.Debug
+  }
+}
+
+// SetupCloudLogging returns a logger that ships entries to Cloud Logging
+// in cfg.GoogleCloudProject, along with a function that flushes buffered
+// entries and closes the client. Without a project it returns cfg.Logger
+// and a no-op.
+func SetupCloudLogging(ctx context.Context, cfg Config) (*slog.Logger, func() error, error) {
+  if cfg.GoogleCloudProject == "" {
+    return cfg.Logger, func() error { return nil }, nil
+  }
+  client, err := logging.NewClient(ctx, cfg.GoogleCloudProject)
+  if err != nil {
+    return nil, nil, fmt.Errorf("creating Cloud Logging client: %w", err)
+  }
+  client.OnError = func(err error) {
+    fmt.Fprintln(os.Stderr, "cloud logging:", err)
+  }
+  logger := client.Logger(cloudLogID)
+  h := &cloudHandler{log: logger.Log, level: cfg.LogLevel}
+  return slog.New(h), func() error {
+    if err := logger.Flush(); err != nil {
+      client.Close()
+      return err
+    }
+    return client.Close()
+  }, nil
+}
@@ -0,0 +1,96 @@
+package timeservice
+
+import (
+  "context"
+  "errors"
+  "log/slog"
+  "net/http/httptest"
+  "testing"
+  "time"
+
+  "cloud.google.com/go/logging"
+)
+
+func TestCloudHandler(t *testing.T) {
+  var entries []logging.Entry
+  h := &cloudHandler{log: func(e logging.Entry) { entries = append(entries, e) }, level: slog.LevelInfo}
+  logger := slog.New(h)
+
+  logger.Debug("dropped")
+  NewHandler(Config{Logger: logger}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/unknown", nil))
+  logger.With("component", "ntp").WithGroup("query").Warn("slow", "server", "pool.ntp.org")
+  logger.WithGroup("ntp").With("server", "pool.ntp.org").Error("query failed",
+    "err", errors.New("i/o timeout"),
+    slog.Group("", "elapsed", 1500*time.Millisecond),
+    slog.Group("at", "time", time.Date(2016, 9, 23, 10, 0, 0, 0, time.UTC)))
+
+  if len(entries) != 3 {
+    t.Fatalf("got %d entries, want 3", len(entries))
+  }
+  req := entries[0]
+  if req.Severity != logging.Warning {
+    t.Errorf("404 request: got severity %v, want Warning", req.Severity)
+  }
+  if req.Labels["path"] != "/v1/unknown" || req.Labels["status"] != "404" {
+    t.Errorf("got labels %v", req.Labels)
+  }
+  payload := req.Payload.(map[string]any)
+  if payload["message"] != "request" || payload["method"] != "GET" {
+    t.Errorf("got payload %v", payload)
+  }
+
+  warn := entries[1]
+  payload = warn.Payload.(map[string]any)
+  if warn.Severity != logging.Warning || payload["component"] != "ntp" || payload["query.server"] != "pool.ntp.org" {
+    t.Errorf("got %v entry with payload %v", warn.Severity, payload)
+  }
+
+  want := map[string]any{
+    "message": "query failed",
+    "ntp.server": "pool.ntp.org",
+    "ntp.err": "i/o timeout",
+    "ntp.elapsed": "1.5s",
+    "ntp.at.time": "2016-09-23T10:00:00Z",
+  }
+  payload = entries[2].Payload.(map[string]any)
+  if len(payload) != len(want) {
+    t.Errorf("got payload %v, want %v", payload, want)
+  }
+  for k, v := range want {
+    if payload[k] != v {
+      t.Errorf("got %s=%#v, want %#v", k, payload[k], v)
+    }
+  }
+}
+
+func TestCloudHandlerRequestSeverity(t *testing.T) {
+  var got logging.Severity
+  logger := slog.New(&cloudHandler{log: func(e logging.Entry) { got = e.Severity }, level: slog.LevelInfo})
+  tests := []struct {
+    msg string
+    status int
+    want logging.Severity
+  }{
+    {"request", 200, logging.Info},
+    {"request", 429, logging.Warning},
+    {"request", 503, logging.Error},
+    {"upstream", 503, logging.Info},
+  }
+  for _, tt := range tests {
+    logger.Info(tt.msg, "status", tt.status)
+    if got != tt.want {
+      t.Errorf("%s with status %d: got severity %v, want %v", tt.msg, tt.status, got, tt.want)
+    }
+  }
+}
+
+func TestSetupCloudLoggingDisabled(t *testing.T) {
+  cfg := testConfig(t)
+  logger, closeLogging, err := SetupCloudLogging(context.Background(), cfg)
+  if err != nil || logger != cfg.Logger {
+    t.Fatalf("expected the stdout logger back, got %v, %v", logger, err)
+  }
+  if err := closeLogging(); err != nil {
+    t.Error(err)
+  }
+}
@@ -0,0 +1,413 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log/slog"
+  "math/rand/v2"
+  "os"
+  "strconv"
+  "strings"
+  "time"
+)
+
+// Config holds every setting for the service. LoadConfig fills it from
+// the environment; the variable for each field is noted alongside it.
+//
+// The defaults noted are LoadConfig's. A Config built by hand gets only
+// its runtime fields filled in by the handlers, and can hold a zero
+// HandlerTimeout, CacheTTL or MaxBodyBytes, which the environment can't:
+// zero turns that limit off.
+//
+// Named time zones need the host's time zone database. Without one, as
+// on scratch images, the process logs a warning at startup and requests
+// naming a zone get 501 Not Implemented; UTC still works. Building with
+// -tags timetzdata embeds the database and avoids this.
+type Config struct {
+  // Addr is the listen address built from PORT, which defaults to 80,
+  // or 443 when TLS is enabled.
+  Addr string
+
+  // ListenSocket, from LISTEN_SOCKET, is the path of a Unix socket to
+  // listen on instead of Addr, for sidecar deployments.
+  ListenSocket string
+
+  // TLSCert and TLSKey are the certificate and key paths from TLS_CERT
+  // and TLS_KEY. Both or neither must be set; when set the server
+  // speaks HTTPS.
+  TLSCert string
+  TLSKey string
+
+  // Greeting, from GREETING, is returned by the root handler to English
+  // speakers and to requests whose Accept-Language matches none of the
+  // translations in greetings. Defaults to "Hi there".
+  Greeting string
+
+  Clock Clock `json:"-"`
+
+  // NTPServer, from NTP_SERVER, is queried at startup to correct Clock
+  // for drift. Empty uses the system clock as is.
+  NTPServer string
+
+  // StartTime is when the process started, read from Clock so uptime is
+  // measured on one clock. main sets it once Clock is settled.
+  StartTime time.Time `json:"-"`
+
+  // State holds the runtime toggles. LoadConfig starts it in
+  // maintenance mode when MAINTENANCE is true.
+  State *State `json:"-"`
+
+  // LogLevel is the minimum level logged, from LOG_LEVEL (debug, info,
+  // warn or error). Defaults to info.
+  LogLevel slog.Level
+
+  // Logger writes JSON to stdout at LogLevel. SetupCloudLogging
+  // replaces it when GoogleCloudProject is set.
+  Logger *slog.Logger `json:"-"`
+
+  // GoogleCloudProject, from GOOGLE_CLOUD_PROJECT, sends logs to Cloud
+  // Logging in that project instead of stdout.
+  GoogleCloudProject string
+
+  // HandlerTimeout, from HANDLER_TIMEOUT, bounds each request handler
+  // other than the streams; slower ones get a JSON 503. It defaults to
+  // 8s, within the default WriteTimeout so the 503 reaches the client.
+  HandlerTimeout time.Duration
+
+  // DrainGracePeriod, from DRAIN_GRACE_PERIOD, is how long the process
+  // keeps serving after POST /admin/drain before shutting down, giving
+  // load balancers time to notice /readyz failing. Defaults to 30s.
+  DrainGracePeriod time.Duration
+
+  // Server timeouts, set from READ_HEADER_TIMEOUT, READ_TIMEOUT,
+  // WRITE_TIMEOUT and IDLE_TIMEOUT as Go durations (e.g. "5s").
+  // Defaults are 5s, 10s, 10s and 60s.
+  ReadHeaderTimeout time.Duration
+  ReadTimeout time.Duration
+  WriteTimeout time.Duration
+  IdleTimeout time.Duration
+
+  // H2C, from H2C, also serves HTTP/2 without TLS (h2c) on the same
+  // port as HTTP/1.1, for proxies that speak it. Defaults to false.
+  H2C bool
+
+  // CacheTTL, from CACHE_TTL, is how long responses that depend only on
+  // their parameters, like /version and /v1/convert, are cached, and
+  // the max-age clients are told. The live time is never cached.
+  // Defaults to 5m.
+  CacheTTL time.Duration
+
+  // AllowedOrigins lists the origins granted CORS access, from the
+  // comma-separated ALLOWED_ORIGINS. "*" allows any origin; empty
+  // disables CORS.
+  AllowedOrigins []string
+
+  // RateLimit is the sustained requests per second allowed per client
+  // IP, from RATE_LIMIT, with bursts of up to RateBurst from RATE_BURST.
+  // Rate limiting is off when RateLimit is 0, the default. RateBurst
+  // defaults to 10.
+  RateLimit float64
+  RateBurst int
+
+  // MaxBodyBytes, from MAX_BODY_BYTES, caps request bodies; larger ones
+  // get 413 Request Entity Too Large. Defaults to 1 MiB.
+  MaxBodyBytes int64
+
+  // Chaos, from CHAOS, makes the root handler misbehave for resilience
+  // testing. Never enable it in production. Each request is delayed by
+  // up to ChaosMaxDelay (CHAOS_MAX_DELAY, default 500ms), and
+  // ChaosErrorPercent of them (CHAOS_ERROR_PERCENT, default 10) fail
+  // with 500. ChaosRand supplies the randomness; nil seeds it from the
+  // current time. Defaults to false.
+  Chaos bool
+  ChaosMaxDelay time.Duration
+  ChaosErrorPercent float64
+  ChaosRand *rand.Rand `json:"-"`
+
+  // TrustProxy, from TRUST_PROXY, takes the client address from
+  // X-Forwarded-For or X-Real-IP. Only enable it behind a proxy that
+  // sets those headers. Defaults to false.
+  TrustProxy bool
+
+  // JSONNaming, from JSON_NAMING, is the style of JSON keys across the
+  // API: NamingCamelCase ("formattedTime", the default) or
+  // NamingSnakeCase ("formatted_time").
+  JSONNaming string
+
+  // Envelope, from ENVELOPE, wraps JSON responses in
+  // {"data": ..., "meta": {...}}. Defaults to false, the flat shape.
+  Envelope bool
+
+  // APIKey, from API_KEY, must be presented as a bearer token or in
+  // X-API-Key on every request except /healthz. Empty disables auth.
+  APIKey string
+
+  // OTLPEndpoint, from OTEL_EXPORTER_OTLP_ENDPOINT, enables exporting
+  // traces over OTLP/HTTP. The exporter also honors the other standard
+  // OTEL_EXPORTER_OTLP_* variables.
+  OTLPEndpoint string
+}
+
+const (
+  defaultPort = "80"
+  defaultTLSPort = "443"
+  defaultGreeting = "Hi there"
+  defaultReadHeaderTimeout = 5 * time.Second
+  defaultReadTimeout = 10 * time.Second
+  defaultWriteTimeout = 10 * time.Second
+  defaultIdleTimeout = 60 * time.Second
+  defaultRateBurst = 10
+  defaultMaxBodyBytes = 1 << 20
+  defaultCacheTTL = 5 * time.Minute
+  defaultDrainGracePeriod = 30 * time.Second
+  defaultHandlerTimeout = 8 * time.Second
+  defaultChaosMaxDelay = 500 * time.Millisecond
+  defaultChaosErrorPercent = 10
+)
+
+// LoadConfig reads the service configuration from the environment,
+// applying defaults. It validates every variable before returning, so
+// the error lists all invalid settings at once.
+func LoadConfig() (Config, error) {
+  var errs []error
+  cfg := Config{Clock: realClock{}, State: &State{}}
+
+  cert, key, err := loadTLS()
+  if err != nil {
+    errs = append(errs, err)
+  }
+  cfg.TLSCert, cfg.TLSKey = cert, key
+
+  port := defaultPort
+  if cfg.TLSCert != "" {
+    port = defaultTLSPort
+  }
+  if cfg.Addr, err = listenAddr(port); err != nil {
+    errs = append(errs, err)
+  }
+
+  cfg.ListenSocket = os.Getenv("LISTEN_SOCKET")
+
+  cfg.Greeting = os.Getenv("GREETING")
+  if cfg.Greeting == "" {
+    cfg.Greeting = defaultGreeting
+  }
+
+  if s := os.Getenv("LOG_LEVEL"); s != "" {
+    if err := cfg.LogLevel.UnmarshalText([]byte(s)); err != nil {
+      errs = append(errs, fmt.Errorf("invalid LOG_LEVEL %q", s))
+    }
+  }
+  cfg.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel}))
+  cfg.GoogleCloudProject = os.Getenv("GOOGLE_CLOUD_PROJECT")
+
+  timeouts := []struct {
+    env string
+    dst *time.Duration
+    def time.Duration
+  }{
+    {"READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout, defaultReadHeaderTimeout},
+    {"READ_TIMEOUT", &cfg.ReadTimeout, defaultReadTimeout},
+    {"WRITE_TIMEOUT", &cfg.WriteTimeout, defaultWriteTimeout},
+    {"IDLE_TIMEOUT", &cfg.IdleTimeout, defaultIdleTimeout},
+  }
+  for _, to := range timeouts {
+    if *to.dst, err = envDuration(to.env, to.def); err != nil {
+      errs = append(errs, err)
+    }
+  }
+
+  if cfg.HandlerTimeout, err = envDuration("HANDLER_TIMEOUT", defaultHandlerTimeout); err != nil {
+    errs = append(errs, err)
+  }
+  if cfg.DrainGracePeriod, err = envDuration("DRAIN_GRACE_PERIOD", defaultDrainGracePeriod); err != nil {
+    errs = append(errs, err)
+  }
+  if cfg.CacheTTL, err = envDuration("CACHE_TTL", defaultCacheTTL); err != nil {
+    errs = append(errs, err)
+  }
+
+  for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+    if origin = strings.TrimSpace(origin); origin != "" {
+      cfg.AllowedOrigins = append(cfg.AllowedOrigins, origin)
+    }
+  }
+
+  if s := os.Getenv("RATE_LIMIT"); s != "" {
+    f, err := strconv.ParseFloat(s, 64)
+    if err != nil || f < 0 {
+      errs = append(errs, fmt.Errorf("invalid RATE_LIMIT %q: must be a non-negative number", s))
+    }
+    cfg.RateLimit = f
+  }
+  cfg.RateBurst = defaultRateBurst
+  if s := os.Getenv("RATE_BURST"); s != "" {
+    n, err := strconv.Atoi(s)
+    if err != nil || n < 1 {
+      errs = append(errs, fmt.Errorf("invalid RATE_BURST %q: must be a positive integer", s))
+    }
+    cfg.RateBurst = n
+  }
+
+  cfg.MaxBodyBytes = defaultMaxBodyBytes
+  if s := os.Getenv("MAX_BODY_BYTES"); s != "" {
+    n, err := strconv.ParseInt(s, 10, 64)
+    if err != nil || n < 1 {
+      errs = append(errs, fmt.Errorf("invalid MAX_BODY_BYTES %q: must be a positive integer", s))
+    }
+    cfg.MaxBodyBytes = n
+  }
+
+  if s := os.Getenv("H2C"); s != "" {
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+      errs = append(errs, fmt.Errorf("invalid H2C %q: must be a boolean", s))
+    }
+    cfg.H2C = b
+  }
+
+  if s := os.Getenv("CHAOS"); s != "" {
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+      errs = append(errs, fmt.Errorf("invalid CHAOS %q: must be a boolean", s))
+    }
+    cfg.Chaos = b
+  }
+  if cfg.ChaosMaxDelay, err = envDuration("CHAOS_MAX_DELAY", defaultChaosMaxDelay); err != nil {
+    errs = append(errs, err)
+  }
+  cfg.ChaosErrorPercent = defaultChaosErrorPercent
+  if s := os.Getenv("CHAOS_ERROR_PERCENT"); s != "" {
+    f, err := strconv.ParseFloat(s, 64)
+    if err != nil || f < 0 || f > 100 {
+      errs = append(errs, fmt.Errorf("invalid CHAOS_ERROR_PERCENT %q: must be a number from 0 to 100", s))
+    }
+    cfg.ChaosErrorPercent = f
+  }
+
+  if s := os.Getenv("TRUST_PROXY"); s != "" {
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+      errs = append(errs, fmt.Errorf("invalid TRUST_PROXY %q: must be a boolean", s))
+    }
+    cfg.TrustProxy = b
+  }
+
+  cfg.JSONNaming = NamingCamelCase
+  if s := os.Getenv("JSON_NAMING"); s != "" {
+    if s != NamingCamelCase && s != NamingSnakeCase {
+      errs = append(errs, fmt.Errorf("invalid JSON_NAMING %q: must be %s or %s", s, NamingCamelCase, NamingSnakeCase))
+    }
+    cfg.JSONNaming = s
+  }
+
+  if s := os.Getenv("ENVELOPE"); s != "" {
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+      errs = append(errs, fmt.Errorf("invalid ENVELOPE %q: must be a boolean", s))
+    }
+    cfg.Envelope = b
+  }
+
+  if s := os.Getenv("MAINTENANCE"); s != "" {
+    on, err := strconv.ParseBool(s)
+    if err != nil {
+      errs = append(errs, fmt.Errorf("invalid MAINTENANCE %q: must be a boolean", s))
+    }
+    cfg.State.SetMaintenance(on)
+  }
+
+  cfg.NTPServer = os.Getenv("NTP_SERVER")
+  cfg.APIKey = os.Getenv("API_KEY")
+  cfg.OTLPEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+
+  if len(errs) > 0 {
+    return Config{}, errors.Join(errs...)
+  }
+  return cfg, nil
+}
+
+const redacted = "REDACTED"
+
+// MarshalJSON renders cfg for display, e.g. by -print-config: secrets are
+// masked, durations are written as strings like "5s", and runtime
+// values such as the logger are left out.
+func (cfg Config) MarshalJSON() ([]byte, error) {
+  type plain Config
+  apiKey := ""
+  if cfg.APIKey != "" {
+    apiKey = redacted
+  }
+  return json.Marshal(struct {
+    plain
+    APIKey string
+    ReadHeaderTimeout string
+    ReadTimeout string
+    WriteTimeout string
+    IdleTimeout string
+    ChaosMaxDelay string
+    CacheTTL string
+    DrainGracePeriod string
+    HandlerTimeout string
+  }{
+    plain: plain(cfg),
+    APIKey: apiKey,
+    ReadHeaderTimeout: cfg.ReadHeaderTimeout.String(),
+    ReadTimeout: cfg.ReadTimeout.String(),
+    WriteTimeout: cfg.WriteTimeout.String(),
+    IdleTimeout: cfg.IdleTimeout.String(),
+    ChaosMaxDelay: cfg.ChaosMaxDelay.String(),
+    CacheTTL: cfg.CacheTTL.String(),
+    DrainGracePeriod: cfg.DrainGracePeriod.String(),
+    HandlerTimeout: cfg.HandlerTimeout.String(),
+  })
+}
+
+// listenAddr builds the listen address from PORT, falling back to
+// defaultPort when it is unset or empty.
+func listenAddr(defaultPort string) (string, error) {
+  port := os.Getenv("PORT")
+  if port == "" {
+    port = defaultPort
+  }
+  n, err := strconv.Atoi(port)
+  if err != nil || n < 1 || n > 65535 {
+    return "", fmt.Errorf("invalid PORT %q: must be an integer between 1 and 65535", port)
+  }
+  return ":" + strconv.Itoa(n), nil
+}
+
+// loadTLS returns the certificate and key paths from TLS_CERT and
+// TLS_KEY. Both must be set, and readable, or neither.
+func loadTLS() (cert, key string, err error) {
+  cert, key = os.Getenv("TLS_CERT"), os.Getenv("TLS_KEY")
+  if cert == "" && key == "" {
+    return "", "", nil
+  }
+  if cert == "" || key == "" {
+    return "", "", errors.New("TLS_CERT and TLS_KEY must be set together")
+  }
+  for _, path := range []string{cert, key} {
+    f, err := os.Open(path)
+    if err != nil {
+      return "", "", fmt.Errorf("cannot read TLS file: %w", err)
+    }
+    f.Close()
+  }
+  return cert, key, nil
+}
+
+// envDuration parses the named variable as a positive duration, returning
+// def when it is unset.
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+  s := os.Getenv(name)
+  if s == "" {
+    return def, nil
+  }
+  d, err := time.ParseDuration(s)
+  if err != nil || d <= 0 {
+    return 0, fmt.Errorf("invalid %s %q: must be a positive duration", name, s)
+  }
+  return d, nil
+}
@@ -0,0 +1,169 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestLoadConfigTimeouts(t *testing.T) {
+  cfg := testConfig(t)
+  if cfg.ReadHeaderTimeout != 5*time.Second || cfg.ReadTimeout != 10*time.Second ||
+    cfg.WriteTimeout != 10*time.Second || cfg.IdleTimeout != 60*time.Second {
+    t.Errorf("unexpected default timeouts %+v", cfg)
+  }
+
+  t.Setenv("WRITE_TIMEOUT", "30s")
+  cfg = testConfig(t)
+  if cfg.WriteTimeout != 30*time.Second {
+    t.Errorf("got WriteTimeout %v, want 30s", cfg.WriteTimeout)
+  }
+
+  for _, v := range []string{"soon", "-1s", "0"} {
+    t.Setenv("IDLE_TIMEOUT", v)
+    if _, err := LoadConfig(); err == nil {
+      t.Errorf("IDLE_TIMEOUT=%q: expected an error", v)
+    }
+  }
+}
+
+func TestListenAddr(t *testing.T) {
+  tests := []struct {
+    port string
+    addr string
+    fail bool
+  }{
+    {"", ":80", false},
+    {"8080", ":8080", false},
+    {"65535", ":65535", false},
+    {"0", "", true},
+    {"65536", "", true},
+    {"http", "", true},
+  }
+  for _, tt := range tests {
+    t.Setenv("PORT", tt.port)
+    addr, err := listenAddr(defaultPort)
+    if tt.fail {
+      if err == nil {
+        t.Errorf("PORT=%q: expected an error, got %q", tt.port, addr)
+      }
+      continue
+    }
+    if err != nil || addr != tt.addr {
+      t.Errorf("PORT=%q: got (%q, %v), want %q", tt.port, addr, err, tt.addr)
+    }
+  }
+}
+
+func TestLoadTLS(t *testing.T) {
+  dir := t.TempDir()
+  cert := filepath.Join(dir, "cert.pem")
+  key := filepath.Join(dir, "key.pem")
+  for _, path := range []string{cert, key} {
+    if err := os.WriteFile(path, []byte("pem"), 0600); err != nil {
+      t.Fatal(err)
+    }
+  }
+  tests := []struct {
+    cert string
+    key string
+    fail bool
+  }{
+    {"", "", false},
+    {cert, key, false},
+    {cert, "", true},
+    {"", key, true},
+    {cert, filepath.Join(dir, "missing.pem"), true},
+  }
+  for _, tt := range tests {
+    t.Setenv("TLS_CERT", tt.cert)
+    t.Setenv("TLS_KEY", tt.key)
+    gotCert, gotKey, err := loadTLS()
+    if tt.fail {
+      if err == nil {
+        t.Errorf("TLS_CERT=%q TLS_KEY=%q: expected an error", tt.cert, tt.key)
+      }
+      continue
+    }
+    if err != nil || gotCert != tt.cert || gotKey != tt.key {
+      t.Errorf("TLS_CERT=%q TLS_KEY=%q: got (%q, %q, %v)", tt.cert, tt.key, gotCert, gotKey, err)
+    }
+  }
+}
+
+func TestLoadConfigAggregatesErrors(t *testing.T) {
+  t.Setenv("PORT", "http")
+  t.Setenv("LOG_LEVEL", "loud")
+  t.Setenv("READ_TIMEOUT", "soon")
+  t.Setenv("TLS_KEY", "key.pem")
+  _, err := LoadConfig()
+  if err == nil {
+    t.Fatal("expected an error")
+  }
+  for _, name := range []string{"PORT", "LOG_LEVEL", "READ_TIMEOUT", "TLS_CERT"} {
+    if !strings.Contains(err.Error(), name) {
+      t.Errorf("error %q does not mention %s", err, name)
+    }
+  }
+}
+
+func TestLoadConfigTLSPort(t *testing.T) {
+  dir := t.TempDir()
+  cert := filepath.Join(dir, "cert.pem")
+  key := filepath.Join(dir, "key.pem")
+  for _, path := range []string{cert, key} {
+    if err := os.WriteFile(path, []byte("pem"), 0600); err != nil {
+      t.Fatal(err)
+    }
+  }
+  t.Setenv("TLS_CERT", cert)
+  t.Setenv("TLS_KEY", key)
+  if cfg := testConfig(t); cfg.Addr != ":443" {
+    t.Errorf("got Addr %q, want :443", cfg.Addr)
+  }
+  t.Setenv("PORT", "8443")
+  if cfg := testConfig(t); cfg.Addr != ":8443" {
+    t.Errorf("got Addr %q, want :8443", cfg.Addr)
+  }
+}
+
+func TestConfigMarshalJSON(t *testing.T) {
+  t.Setenv("API_KEY", "s3cret")
+  t.Setenv("PORT", "8080")
+  js, err := json.Marshal(testConfig(t))
+  if err != nil {
+    t.Fatal(err)
+  }
+  if strings.Contains(string(js), "s3cret") {
+    t.Errorf("API key leaked: %s", js)
+  }
+  var got map[string]any
+  if err := json.Unmarshal(js, &got); err != nil {
+    t.Fatal(err)
+  }
+  want := map[string]any{
+    "APIKey": "REDACTED",
+    "Addr": ":8080",
+    "ReadHeaderTimeout": "5s",
+    "LogLevel": "INFO",
+  }
+  for k, v := range want {
+    if got[k] != v {
+      t.Errorf("%s = %v, want %v", k, got[k], v)
+    }
+  }
+  for _, k := range []string{"Clock", "Logger", "State", "plain"} {
+    if _, ok := got[k]; ok {
+      t.Errorf("unexpected field %s", k)
+    }
+  }
+
+  t.Setenv("API_KEY", "")
+  js, _ = json.Marshal(testConfig(t))
+  if strings.Contains(string(js), redacted) {
+    t.Errorf("unset API key shown as redacted: %s", js)
+  }
+}
@@ -0,0 +1,75 @@
+package timeservice
+
+import (
+  "errors"
+  "fmt"
+  "net/http"
+  "time"
+)
+
+type ConvertResult struct {
+  From string `json:"from"`
+  To string `json:"to"`
+  FromOffset int `json:"fromOffset"`
+  ToOffset int `json:"toOffset"`
+}
+
+// queryLocation loads the IANA zone named by the required query
+// parameter param.
+func queryLocation(r *http.Request, param string) (*time.Location, error) {
+  name := r.URL.Query().Get(param)
+  if name == "" {
+    return nil, fmt.Errorf("missing %s", param)
+  }
+  loc, err := lookupLocation(name)
+  if errors.Is(err, errNoTZData) {
+    return nil, err
+  }
+  if err != nil {
+    return nil, fmt.Errorf("invalid %s: unknown time zone %q", param, name)
+  }
+  return loc, nil
+}
+
+// queryTime parses the required RFC3339 query parameter param.
+func queryTime(r *http.Request, param string) (time.Time, error) {
+  s := r.URL.Query().Get(param)
+  if s == "" {
+    return time.Time{}, fmt.Errorf("missing %s", param)
+  }
+  t, err := time.Parse(time.RFC3339, s)
+  if err != nil {
+    return time.Time{}, fmt.Errorf("invalid %s %q: must be RFC3339", param, s)
+  }
+  return t, nil
+}
+
+// ConvertHandler renders the instant given by the time parameter in the
+// from and to zones, along with each zone's offset at that instant.
+func ConvertHandler(w http.ResponseWriter, r *http.Request) {
+  t, err := queryTime(r, "time")
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  from, err := queryLocation(r, "from")
+  if err != nil {
+    writeLocationError(w, err)
+    return
+  }
+  to, err := queryLocation(r, "to")
+  if err != nil {
+    writeLocationError(w, err)
+    return
+  }
+
+  inFrom, inTo := t.In(from), t.In(to)
+  _, fromOffset := inFrom.Zone()
+  _, toOffset := inTo.Zone()
+  writeJSON(w, r, ConvertResult{
+    From: inFrom.Format(time.RFC3339),
+    To: inTo.Format(time.RFC3339),
+    FromOffset: fromOffset,
+    ToOffset: toOffset,
+  })
+}
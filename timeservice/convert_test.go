@@ -0,0 +1,50 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestConvert(t *testing.T) {
+  tests := []struct {
+    query string
+    status int
+    want ConvertResult
+    errField string
+  }{
+    {"time=2016-09-23T10:39:00Z&from=Europe/Lisbon&to=Asia/Tokyo", http.StatusOK,
+      ConvertResult{"2016-09-23T11:39:00+01:00", "2016-09-23T19:39:00+09:00", 3600, 32400}, ""},
+    {"time=2016-01-15T12:00:00-05:00&from=America/New_York&to=UTC", http.StatusOK,
+      ConvertResult{"2016-01-15T12:00:00-05:00", "2016-01-15T17:00:00Z", -18000, 0}, ""},
+    {"from=UTC&to=UTC", http.StatusBadRequest, ConvertResult{}, "time"},
+    {"time=yesterday&from=UTC&to=UTC", http.StatusBadRequest, ConvertResult{}, "time"},
+    {"time=2016-09-23T10:39:00Z&to=UTC", http.StatusBadRequest, ConvertResult{}, "from"},
+    {"time=2016-09-23T10:39:00Z&from=UTC&to=Nowhere/Special", http.StatusBadRequest, ConvertResult{}, "to"},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    ConvertHandler(rec, httptest.NewRequest("GET", "/convert?"+tt.query, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%q: got status %d, want %d", tt.query, rec.Code, tt.status)
+      continue
+    }
+    if tt.status != http.StatusOK {
+      var er ErrorResult
+      if err := json.Unmarshal(rec.Body.Bytes(), &er); err != nil || !strings.Contains(er.Error, tt.errField) {
+        t.Errorf("%q: expected an error about %s, got %q", tt.query, tt.errField, rec.Body.String())
+      }
+      continue
+    }
+    var got ConvertResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+      t.Errorf("%q: %v", tt.query, err)
+      continue
+    }
+    if got != tt.want {
+      t.Errorf("%q: got %+v, want %+v", tt.query, got, tt.want)
+    }
+  }
+}
@@ -0,0 +1,28 @@
+package timeservice
+
+import (
+  "net/http"
+)
+
+type DurationResult struct {
+  Seconds float64 `json:"seconds"`
+  Duration string `json:"duration"`
+}
+
+// DurationHandler reports the span from start to end, which is negative
+// when end is before start.
+func DurationHandler(w http.ResponseWriter, r *http.Request) {
+  start, err := queryTime(r, "start")
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  end, err := queryTime(r, "end")
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  d := end.Sub(start)
+  writeJSON(w, r, DurationResult{d.Seconds(), d.String()})
+}
@@ -0,0 +1,43 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestDuration(t *testing.T) {
+  tests := []struct {
+    name string
+    query string
+    status int
+    want DurationResult
+  }{
+    {"forward", "start=2016-09-23T10:00:00Z&end=2016-09-23T12:03:04Z", http.StatusOK, DurationResult{7384, "2h3m4s"}},
+    {"backward", "start=2016-09-23T12:03:04Z&end=2016-09-23T10:00:00Z", http.StatusOK, DurationResult{-7384, "-2h3m4s"}},
+    {"across zones", "start=2016-09-23T10:00:00Z&end=2016-09-23T11:00:00%2B01:00", http.StatusOK, DurationResult{0, "0s"}},
+    {"fractional", "start=2016-09-23T10:00:00Z&end=2016-09-23T10:00:01.5Z", http.StatusOK, DurationResult{1.5, "1.5s"}},
+    {"missing end", "start=2016-09-23T10:00:00Z", http.StatusBadRequest, DurationResult{}},
+    {"malformed start", "start=10am&end=2016-09-23T10:00:00Z", http.StatusBadRequest, DurationResult{}},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    DurationHandler(rec, httptest.NewRequest("GET", "/duration?"+tt.query, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.status)
+      continue
+    }
+    if tt.status != http.StatusOK {
+      continue
+    }
+    var got DurationResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+      t.Errorf("%s: %v", tt.name, err)
+      continue
+    }
+    if got != tt.want {
+      t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+    }
+  }
+}
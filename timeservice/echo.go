@@ -0,0 +1,43 @@
+package timeservice
+
+import (
+  "net/http"
+)
+
+// redactedHeaders carry credentials and are never echoed back.
+var redactedHeaders = map[string]bool{
+  "Authorization": true,
+  "Proxy-Authorization": true,
+  "Cookie": true,
+  "X-Api-Key": true,
+}
+
+// EchoResult is what /echo reports. Query and Headers keep the names as
+// they were received, whatever Config.JSONNaming asks for.
+type EchoResult struct {
+  Method string `json:"method"`
+  Path string `json:"path"`
+  Query map[string][]string `json:"query"`
+  Headers map[string][]string `json:"headers"`
+  ClientIP string `json:"clientIp"`
+  TLS bool `json:"tls"`
+}
+
+// EchoHandler reflects what the server received, for debugging proxies
+// and clients. Credential headers are left out.
+func EchoHandler(w http.ResponseWriter, r *http.Request) {
+  headers := make(map[string][]string, len(r.Header))
+  for name, values := range r.Header {
+    if !redactedHeaders[http.CanonicalHeaderKey(name)] {
+      headers[name] = values
+    }
+  }
+  writeJSON(w, r, EchoResult{
+    Method: r.Method,
+    Path: r.URL.Path,
+    Query: r.URL.Query(),
+    Headers: headers,
+    ClientIP: clientIP(r),
+    TLS: r.TLS != nil,
+  })
+}
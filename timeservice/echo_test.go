@@ -0,0 +1,65 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestEchoHandler(t *testing.T) {
+  t.Setenv("TRUST_PROXY", "true")
+  h := NewHandler(testConfig(t))
+  req := httptest.NewRequest("GET", "/echo?tz=UTC&tz=Asia/Tokyo", nil)
+  req.Header.Set("X-Forwarded-For", "203.0.113.7")
+  req.Header.Set("X-Custom", "yes")
+  req.Header.Set("Authorization", "Bearer s3cret")
+  req.Header.Set("Cookie", "session=s3cret")
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, req)
+
+  var got EchoResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+    t.Fatal(err)
+  }
+  if got.Method != "GET" || got.Path != "/echo" || got.TLS {
+    t.Errorf("got method %q, path %q, tls %v", got.Method, got.Path, got.TLS)
+  }
+  if len(got.Query["tz"]) != 2 || got.Query["tz"][1] != "Asia/Tokyo" {
+    t.Errorf("got query %v", got.Query)
+  }
+  if got.ClientIP != "203.0.113.7" {
+    t.Errorf("got client IP %q", got.ClientIP)
+  }
+  if v := got.Headers["X-Custom"]; len(v) != 1 || v[0] != "yes" {
+    t.Errorf("missing X-Custom in %v", got.Headers)
+  }
+  for _, name := range []string{"Authorization", "Cookie"} {
+    if _, ok := got.Headers[name]; ok {
+      t.Errorf("%s was echoed", name)
+    }
+  }
+
+  cfg := testConfig(t)
+  cfg.JSONNaming = NamingSnakeCase
+  req = httptest.NewRequest("GET", "/echo?clientTime=now", nil)
+  req.Header.Set("X-Custom-Header", "yes")
+  rec = httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, req)
+  var snake struct {
+    Query map[string][]string `json:"query"`
+    Headers map[string][]string `json:"headers"`
+    ClientIP *string `json:"client_ip"`
+  }
+  if err := json.Unmarshal(rec.Body.Bytes(), &snake); err != nil {
+    t.Fatal(err)
+  }
+  if snake.ClientIP == nil {
+    t.Errorf("snake_case: missing client_ip in %s", rec.Body)
+  }
+  if _, ok := snake.Query["clientTime"]; !ok {
+    t.Errorf("snake_case: query keys were renamed: %v", snake.Query)
+  }
+  if _, ok := snake.Headers["X-Custom-Header"]; !ok {
+    t.Errorf("snake_case: header names were renamed: %v", snake.Headers)
+  }
+}
@@ -0,0 +1,35 @@
+package timeservice
+
+import (
+  "context"
+  "net/http"
+  "time"
+)
+
+// Envelope wraps JSON responses when Config.Envelope is set.
+type Envelope struct {
+  Data any `json:"data"`
+  Meta EnvelopeMeta `json:"meta"`
+}
+
+type EnvelopeMeta struct {
+  RequestID string `json:"requestId"`
+  ServerTime time.Time `json:"serverTime"`
+}
+
+type envelopeKey struct{}
+
+// envelopeClock returns the clock for the envelope's serverTime, and
+// whether responses to the request are enveloped at all.
+func envelopeClock(ctx context.Context) (Clock, bool) {
+  clock, ok := ctx.Value(envelopeKey{}).(Clock)
+  return clock, ok
+}
+
+// withEnvelope turns on enveloped JSON for the requests it serves.
+func withEnvelope(clock Clock, next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ctx := context.WithValue(r.Context(), envelopeKey{}, clock)
+    next.ServeHTTP(w, r.WithContext(ctx))
+  })
+}
@@ -0,0 +1,46 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestEnvelope(t *testing.T) {
+  now := time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC)
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(now)
+
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("X-Request-ID", "abc123")
+  rec := httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, req)
+  var flat ServiceResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &flat); err != nil {
+    t.Fatal(err)
+  }
+  if flat.FormattedTime != "23 Sep 16 10:39 +0000" {
+    t.Errorf("disabled: got %q, want the flat shape", rec.Body.String())
+  }
+
+  cfg.Envelope = true
+  rec = httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, req)
+  var env struct {
+    Data ServiceResult `json:"data"`
+    Meta EnvelopeMeta `json:"meta"`
+  }
+  if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+    t.Fatal(err)
+  }
+  if env.Data.FormattedTime != "23 Sep 16 10:39 +0000" {
+    t.Errorf("enabled: got data %+v", env.Data)
+  }
+  if env.Meta.RequestID != "abc123" {
+    t.Errorf("enabled: got requestId %q, want %q", env.Meta.RequestID, "abc123")
+  }
+  if !env.Meta.ServerTime.Equal(now) {
+    t.Errorf("enabled: got serverTime %v, want %v", env.Meta.ServerTime, now)
+  }
+}
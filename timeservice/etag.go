@@ -0,0 +1,41 @@
+package timeservice
+
+import (
+  "crypto/sha256"
+  "encoding/hex"
+  "net/http"
+  "strings"
+)
+
+// weakETag derives a weak validator from a response body.
+func weakETag(body []byte) string {
+  sum := sha256.Sum256(body)
+  return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. Weak
+// comparison applies, so the W/ prefix is ignored on both sides.
+func etagMatches(ifNoneMatch, etag string) bool {
+  etag = strings.TrimPrefix(etag, "W/")
+  for _, candidate := range strings.Split(ifNoneMatch, ",") {
+    candidate = strings.TrimSpace(candidate)
+    if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+      return true
+    }
+  }
+  return false
+}
+
+// writeCacheable writes a body that is stable between requests, with an
+// ETag, answering 304 Not Modified when the client already has it. It
+// must not be used for the live time endpoints.
+func writeCacheable(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+  etag := weakETag(body)
+  w.Header().Set("ETag", etag)
+  if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+    w.WriteHeader(http.StatusNotModified)
+    return
+  }
+  w.Header().Set("Content-Type", contentType)
+  w.Write(body)
+}
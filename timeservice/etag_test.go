@@ -0,0 +1,41 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestVersionETag(t *testing.T) {
+  rec := httptest.NewRecorder()
+  VersionHandler(rec, httptest.NewRequest("GET", "/version", nil))
+  etag := rec.Header().Get("ETag")
+  if rec.Code != http.StatusOK || etag == "" || rec.Body.Len() == 0 {
+    t.Fatalf("got status %d, ETag %q and %d body bytes", rec.Code, etag, rec.Body.Len())
+  }
+
+  tests := []struct {
+    ifNoneMatch string
+    status int
+  }{
+    {etag, http.StatusNotModified},
+    {`"other", ` + etag, http.StatusNotModified},
+    {"*", http.StatusNotModified},
+    {`W/"other"`, http.StatusOK},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest("GET", "/version", nil)
+    req.Header.Set("If-None-Match", tt.ifNoneMatch)
+    rec := httptest.NewRecorder()
+    VersionHandler(rec, req)
+    if rec.Code != tt.status {
+      t.Errorf("If-None-Match %q: got status %d, want %d", tt.ifNoneMatch, rec.Code, tt.status)
+    }
+    if tt.status == http.StatusNotModified && rec.Body.Len() != 0 {
+      t.Errorf("If-None-Match %q: expected an empty body on 304", tt.ifNoneMatch)
+    }
+    if rec.Header().Get("ETag") != etag {
+      t.Errorf("If-None-Match %q: ETag changed to %q", tt.ifNoneMatch, rec.Header().Get("ETag"))
+    }
+  }
+}
@@ -0,0 +1,37 @@
+package timeservice
+
+import (
+  "strings"
+)
+
+// greetingLanguage is the language of Config.Greeting. Requests that
+// prefer it get the greeting as configured rather than a translation.
+const greetingLanguage = "en"
+
+// greetings translates the greeting by language tag. Tags are lower
+// case; add an entry here to support another language.
+var greetings = map[string]string{
+  "es": "Hola",
+  "pt": "Olá",
+  "fr": "Salut",
+}
+
+// greetingFor picks the translation best matching an Accept-Language
+// header, trying each language's full tag and then its primary subtag
+// (so "pt-BR" falls back to "pt"). It returns def, the configured
+// greeting, for greetingLanguage and when nothing matches.
+func greetingFor(acceptLanguage, def string) string {
+  for _, lang := range parseWeighted(acceptLanguage) {
+    if g, ok := greetings[lang.value]; ok {
+      return g
+    }
+    primary, _, _ := strings.Cut(lang.value, "-")
+    if primary == greetingLanguage {
+      return def
+    }
+    if g, ok := greetings[primary]; ok {
+      return g
+    }
+  }
+  return def
+}
@@ -0,0 +1,43 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestGreetingFor(t *testing.T) {
+  tests := []struct {
+    acceptLanguage string
+    want string
+  }{
+    {"", "Howdy"},
+    {"es", "Hola"},
+    {"pt-BR", "Olá"},
+    {"de-DE, fr;q=0.7, en;q=0.8", "Howdy"},
+    {"en-US,en;q=0.9,es;q=0.8", "Howdy"},
+    {"en;q=0.3, fr-CA;q=0.9", "Salut"},
+    {"de, ja", "Howdy"},
+    {"*", "Howdy"},
+    {"es;q=0, pt;q=0.1", "Olá"},
+  }
+  for _, tt := range tests {
+    if got := greetingFor(tt.acceptLanguage, "Howdy"); got != tt.want {
+      t.Errorf("Accept-Language %q: got %q, want %q", tt.acceptLanguage, got, tt.want)
+    }
+  }
+}
+
+func TestHandlerAcceptLanguage(t *testing.T) {
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("Accept-Language", "fr-FR;q=0.5, es-MX")
+  rec := httptest.NewRecorder()
+  TimeHandler(testConfig(t))(rec, req)
+  var sr ServiceResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &sr); err != nil {
+    t.Fatal(err)
+  }
+  if sr.Greeting != "Hola" {
+    t.Errorf("got greeting %q, want %q", sr.Greeting, "Hola")
+  }
+}
@@ -0,0 +1,87 @@
+package timeservice
+
+import (
+  "compress/gzip"
+  "net/http"
+)
+
+// gzipResponseWriter compresses the body once the handler commits to a
+// status that carries one.
+type gzipResponseWriter struct {
+  http.ResponseWriter
+  gz *gzip.Writer
+  wroteHeader bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+  if g.wroteHeader {
+    return
+  }
+  g.wroteHeader = true
+  h := g.Header()
+  if status != http.StatusNoContent && status != http.StatusNotModified && h.Get("Content-Encoding") == "" {
+    h.Del("Content-Length")
+    h.Set("Content-Encoding", "gzip")
+    g.gz = gzip.NewWriter(g.ResponseWriter)
+  }
+  g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+  if !g.wroteHeader {
+    if g.Header().Get("Content-Type") == "" {
+      g.Header().Set("Content-Type", http.DetectContentType(b))
+    }
+    g.WriteHeader(http.StatusOK)
+  }
+  if g.gz == nil {
+    return g.ResponseWriter.Write(b)
+  }
+  return g.gz.Write(b)
+}
+
+// FlushError pushes any buffered compressed data to the client, so
+// streaming handlers keep working behind the middleware.
+func (g *gzipResponseWriter) FlushError() error {
+  if g.gz != nil {
+    if err := g.gz.Flush(); err != nil {
+      return err
+    }
+  }
+  return http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+  return g.ResponseWriter
+}
+
+func (g *gzipResponseWriter) close() error {
+  if g.gz == nil {
+    return nil
+  }
+  return g.gz.Close()
+}
+
+func acceptsGzip(r *http.Request) bool {
+  for _, enc := range parseWeighted(r.Header.Get("Accept-Encoding")) {
+    if enc.value == "gzip" || enc.value == "*" {
+      return true
+    }
+  }
+  return false
+}
+
+// gzipResponses compresses responses for clients that advertise gzip in
+// Accept-Encoding. Protocol upgrades are passed through untouched.
+func gzipResponses(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Add("Vary", "Accept-Encoding")
+    if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || !acceptsGzip(r) {
+      next.ServeHTTP(w, r)
+      return
+    }
+    gw := &gzipResponseWriter{ResponseWriter: w}
+    defer gw.close()
+    next.ServeHTTP(gw, r)
+  })
+}
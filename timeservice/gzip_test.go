@@ -0,0 +1,61 @@
+package timeservice
+
+import (
+  "compress/gzip"
+  "io"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestGzipResponses(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  h := gzipResponses(TimeHandler(cfg))
+  want := `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`
+
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, req)
+  if enc := rec.Header().Get("Content-Encoding"); enc != "gzip" {
+    t.Fatalf("got Content-Encoding %q, want gzip", enc)
+  }
+  if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+    t.Errorf("got Content-Type %q", ct)
+  }
+  zr, err := gzip.NewReader(rec.Body)
+  if err != nil {
+    t.Fatal(err)
+  }
+  body, err := io.ReadAll(zr)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(body) != want {
+    t.Errorf("got body %q, want %q", body, want)
+  }
+
+  rec = httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+  if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+    t.Errorf("got Content-Encoding %q without Accept-Encoding", enc)
+  }
+  if rec.Body.String() != want {
+    t.Errorf("got body %q, want %q", rec.Body.String(), want)
+  }
+}
+
+func TestGzipResponsesNoContent(t *testing.T) {
+  h := gzipResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusNoContent)
+  }))
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("Accept-Encoding", "gzip")
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, req)
+  if enc := rec.Header().Get("Content-Encoding"); enc != "" || rec.Body.Len() != 0 {
+    t.Errorf("got Content-Encoding %q and %d body bytes for a 204", enc, rec.Body.Len())
+  }
+}
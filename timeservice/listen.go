@@ -0,0 +1,26 @@
+package timeservice
+
+import (
+  "fmt"
+  "net"
+  "os"
+)
+
+// Listen opens the listener the server should Serve on: the Unix socket
+// cfg.ListenSocket when set, otherwise TCP on cfg.Addr. A stale socket
+// left by an earlier process is removed first. The socket file is
+// removed again when the listener is closed, which Server.Shutdown does.
+func Listen(cfg Config) (net.Listener, error) {
+  if cfg.ListenSocket == "" {
+    return net.Listen("tcp", cfg.Addr)
+  }
+  if fi, err := os.Lstat(cfg.ListenSocket); err == nil {
+    if fi.Mode()&os.ModeSocket == 0 {
+      return nil, fmt.Errorf("LISTEN_SOCKET %q exists and is not a socket", cfg.ListenSocket)
+    }
+    if err := os.Remove(cfg.ListenSocket); err != nil {
+      return nil, err
+    }
+  }
+  return net.Listen("unix", cfg.ListenSocket)
+}
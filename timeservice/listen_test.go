@@ -0,0 +1,69 @@
+package timeservice
+
+import (
+  "context"
+  "net"
+  "net/http"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+func TestListenUnixSocket(t *testing.T) {
+  // Keep the path short: socket paths are limited to about 100 bytes.
+  dir, err := os.MkdirTemp("", "servertime")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer os.RemoveAll(dir)
+  cfg := testConfig(t)
+  cfg.ListenSocket = filepath.Join(dir, "s.sock")
+
+  // Leave a stale socket behind, as a crashed process would.
+  stale, err := net.Listen("unix", cfg.ListenSocket)
+  if err != nil {
+    t.Fatal(err)
+  }
+  stale.(*net.UnixListener).SetUnlinkOnClose(false)
+  stale.Close()
+
+  ln, err := Listen(cfg)
+  if err != nil {
+    t.Fatal(err)
+  }
+  server := NewServer(cfg)
+  go server.Serve(ln)
+
+  client := &http.Client{Transport: &http.Transport{
+    DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+      var d net.Dialer
+      return d.DialContext(ctx, "unix", cfg.ListenSocket)
+    },
+  }}
+  resp, err := client.Get("http://unix/healthz")
+  if err != nil {
+    t.Fatal(err)
+  }
+  resp.Body.Close()
+  if resp.StatusCode != http.StatusOK {
+    t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+  }
+
+  if err := server.Shutdown(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  if _, err := os.Stat(cfg.ListenSocket); !os.IsNotExist(err) {
+    t.Errorf("socket not removed on shutdown: %v", err)
+  }
+}
+
+func TestListenRefusesNonSocket(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.ListenSocket = filepath.Join(t.TempDir(), "file")
+  if err := os.WriteFile(cfg.ListenSocket, nil, 0o600); err != nil {
+    t.Fatal(err)
+  }
+  if _, err := Listen(cfg); err == nil {
+    t.Error("expected an error for a regular file")
+  }
+}
@@ -0,0 +1,102 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "strconv"
+  "time"
+)
+
+const maintenanceRetryAfter = 60 * time.Second
+
+type MaintenanceStatus struct {
+  Enabled bool `json:"enabled"`
+}
+
+// maintenanceExempt stay available during maintenance: the liveness
+// probe and the admin endpoints.
+var maintenanceExempt = map[string]bool{
+  "/healthz": true,
+  "/admin/maintenance": true,
+  "/admin/drain": true,
+}
+
+// maintenanceMode answers 503 Service Unavailable while state is in
+// maintenance.
+func maintenanceMode(state *State, next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if state.InMaintenance() && !maintenanceExempt[r.URL.Path] {
+      w.Header().Set("Retry-After", strconv.Itoa(int(maintenanceRetryAfter.Seconds())))
+      writeError(w, http.StatusServiceUnavailable, "down for maintenance")
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
+
+// requireAdmin guards admin endpoints with cfg.APIKey. Unlike the
+// site-wide check, it refuses everything when no key is configured.
+func requireAdmin(cfg Config, next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if cfg.APIKey == "" {
+      writeError(w, http.StatusForbidden, "admin endpoints are disabled without API_KEY")
+      return
+    }
+    if !validAPIKey(r, cfg.APIKey) {
+      w.Header().Set("WWW-Authenticate", "Bearer")
+      writeError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+      return
+    }
+    next(w, r)
+  }
+}
+
+// MaintenanceHandler switches maintenance mode from a POSTed
+// MaintenanceStatus and reports the resulting state.
+func MaintenanceHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return requireAdmin(cfg, func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+      w.Header().Set("Allow", http.MethodPost)
+      writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+      return
+    }
+    var status MaintenanceStatus
+    if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+      writeBodyError(w, err)
+      return
+    }
+    cfg.State.SetMaintenance(status.Enabled)
+    cfg.Logger.Info("maintenance mode changed", "enabled", status.Enabled)
+    writeJSON(w, r, MaintenanceStatus{cfg.State.InMaintenance()})
+  })
+}
+
+type DrainStatus struct {
+  Draining bool `json:"draining"`
+  GracePeriod string `json:"gracePeriod"`
+}
+
+// DrainHandler starts a drain on POST: /readyz fails from then on, so
+// load balancers stop sending traffic, and the process shuts down
+// gracefully once cfg.DrainGracePeriod has passed.
+func DrainHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return requireAdmin(cfg, func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+      w.Header().Set("Allow", http.MethodPost)
+      writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+      return
+    }
+    cfg.State.Drain()
+    cfg.Logger.Info("draining", "grace_period", cfg.DrainGracePeriod)
+    js, err := marshalJSON(r, DrainStatus{true, cfg.DrainGracePeriod.String()})
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+    w.Header().Set("Content-Type", contentTypeJSON)
+    w.WriteHeader(http.StatusAccepted)
+    w.Write(js)
+  })
+}
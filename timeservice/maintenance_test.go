@@ -0,0 +1,127 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+  t.Setenv("MAINTENANCE", "true")
+  cfg := testConfig(t)
+  h := NewHandler(cfg)
+
+  tests := []struct {
+    path string
+    status int
+  }{
+    {"/", http.StatusServiceUnavailable},
+    {"/v1/now", http.StatusServiceUnavailable},
+    {"/version", http.StatusServiceUnavailable},
+    {"/healthz", http.StatusOK},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.path, rec.Code, tt.status)
+    }
+    if tt.status == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+      t.Errorf("%s: missing Retry-After", tt.path)
+    }
+  }
+}
+
+func TestMaintenanceToggle(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.APIKey = "s3cret"
+  h := NewHandler(cfg)
+  toggle := func(body, key string) int {
+    req := httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(body))
+    if key != "" {
+      req.Header.Set("Authorization", "Bearer "+key)
+    }
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec.Code
+  }
+  get := func() int {
+    req := httptest.NewRequest("GET", "/", nil)
+    req.Header.Set("X-API-Key", "s3cret")
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec.Code
+  }
+
+  if code := toggle(`{"Enabled":true}`, ""); code != http.StatusUnauthorized {
+    t.Errorf("without a key: got status %d", code)
+  }
+  if code := toggle(`{"Enabled":true}`, "s3cret"); code != http.StatusOK {
+    t.Fatalf("enabling: got status %d", code)
+  }
+  if code := get(); code != http.StatusServiceUnavailable {
+    t.Errorf("in maintenance: got status %d", code)
+  }
+  if code := toggle(`{"Enabled":false}`, "s3cret"); code != http.StatusOK {
+    t.Fatalf("disabling: got status %d", code)
+  }
+  if code := get(); code != http.StatusOK {
+    t.Errorf("after maintenance: got status %d", code)
+  }
+
+  rec := httptest.NewRecorder()
+  NewHandler(testConfig(t)).ServeHTTP(rec, httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(`{"Enabled":true}`)))
+  if rec.Code != http.StatusForbidden {
+    t.Errorf("without API_KEY configured: got status %d", rec.Code)
+  }
+}
+
+func TestDrain(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.APIKey = "s3cret"
+  cfg.State.SetReady(true)
+  h := NewHandler(cfg)
+  get := func(path string) int {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+    return rec.Code
+  }
+  drain := func(key string) int {
+    req := httptest.NewRequest("POST", "/admin/drain", nil)
+    req.Header.Set("Authorization", "Bearer "+key)
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec.Code
+  }
+
+  if code := drain("wrong"); code != http.StatusUnauthorized {
+    t.Errorf("bad key: got status %d", code)
+  }
+  if code := get("/readyz"); code != http.StatusOK {
+    t.Fatalf("before drain: /readyz got status %d", code)
+  }
+  if code := drain("s3cret"); code != http.StatusAccepted {
+    t.Errorf("drain: got status %d, want %d", code, http.StatusAccepted)
+  }
+  select {
+  case <-cfg.State.Draining():
+  default:
+    t.Error("Draining not signalled")
+  }
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+  if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != `{"status":"draining"}` {
+    t.Errorf("after drain: /readyz got %d %s, want %d {\"status\":\"draining\"}", rec.Code, rec.Body, http.StatusServiceUnavailable)
+  }
+  if code := get("/healthz"); code != http.StatusOK {
+    t.Errorf("after drain: /healthz got status %d, want %d", code, http.StatusOK)
+  }
+
+  // Neither a second drain nor startup code can put us back in rotation.
+  drain("s3cret")
+  cfg.State.SetReady(true)
+  if code := get("/readyz"); code != http.StatusServiceUnavailable {
+    t.Errorf("after SetReady: /readyz got status %d", code)
+  }
+}
@@ -0,0 +1,63 @@
+package timeservice
+
+import (
+  "net/http"
+  "strconv"
+  "time"
+
+  "github.com/prometheus/client_golang/prometheus"
+  "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+  metricsRegistry *prometheus.Registry
+  requestsTotal *prometheus.CounterVec
+  requestDuration *prometheus.HistogramVec
+)
+
+func init() {
+  resetMetrics()
+}
+
+// resetMetrics replaces the registry and its collectors so tests can
+// start from zero.
+func resetMetrics() {
+  metricsRegistry = prometheus.NewRegistry()
+  requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: "servertime_http_requests_total",
+    Help: "HTTP requests served, by route and status code.",
+  }, []string{"path", "code"})
+  requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+    Name: "servertime_http_request_duration_seconds",
+    Help: "HTTP request latency, by route.",
+    Buckets: prometheus.DefBuckets,
+  }, []string{"path"})
+  metricsRegistry.MustRegister(requestsTotal, requestDuration)
+}
+
+// MetricsHandler exposes the service's metrics in the Prometheus text
+// format.
+func MetricsHandler() http.Handler {
+  return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// instrument records request counts and latencies for next, which should
+// wrap a ServeMux. The path label is the matched route pattern rather
+// than the raw URL, which keeps label cardinality bounded.
+func instrument(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    start := time.Now()
+    rec := &statusRecorder{ResponseWriter: w}
+    next.ServeHTTP(rec, r)
+    if rec.status == 0 {
+      rec.status = http.StatusOK
+    }
+    nameSpan(r)
+    path := r.Pattern
+    if path == "" {
+      path = "unmatched"
+    }
+    requestsTotal.WithLabelValues(path, strconv.Itoa(rec.status)).Inc()
+    requestDuration.WithLabelValues(path).Observe(time.Since(start).Seconds())
+  })
+}
@@ -0,0 +1,42 @@
+package timeservice
+
+import (
+  "net/http/httptest"
+  "testing"
+
+  "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrument(t *testing.T) {
+  resetMetrics()
+  h := NewHandler(testConfig(t))
+
+  h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+  h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?format=iso", nil))
+  h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+  tests := []struct {
+    path string
+    code string
+    want float64
+  }{
+    {"/", "200", 1},
+    {"/", "400", 1},
+    {"/healthz", "200", 1},
+  }
+  for _, tt := range tests {
+    got := testutil.ToFloat64(requestsTotal.WithLabelValues(tt.path, tt.code))
+    if got != tt.want {
+      t.Errorf("requests{path=%q,code=%q} = %v, want %v", tt.path, tt.code, got, tt.want)
+    }
+  }
+  if n := testutil.CollectAndCount(requestDuration); n != 2 {
+    t.Errorf("got %d latency series, want 2", n)
+  }
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+  if rec.Code != 200 {
+    t.Errorf("/metrics returned %d", rec.Code)
+  }
+}
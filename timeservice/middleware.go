@@ -0,0 +1,188 @@
+package timeservice
+
+import (
+  "bufio"
+  "log/slog"
+  "net"
+  "net/http"
+  "runtime/debug"
+  "slices"
+  "time"
+)
+
+// statusRecorder captures the status code and body size written by the
+// wrapped handler.
+type statusRecorder struct {
+  http.ResponseWriter
+  status int
+  size int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+  if rec.status == 0 {
+    rec.status = status
+  }
+  rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+  if rec.status == 0 {
+    rec.status = http.StatusOK
+  }
+  n, err := rec.ResponseWriter.Write(b)
+  rec.size += n
+  return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+  return rec.ResponseWriter
+}
+
+// Hijack supports WebSocket upgrades, whose libraries type-assert
+// http.Hijacker rather than going through http.ResponseController.
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+  conn, brw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+  if err == nil && rec.status == 0 {
+    rec.status = http.StatusSwitchingProtocols
+  }
+  return conn, brw, err
+}
+
+// unloggedPaths are polled often enough that logging them is just noise.
+var unloggedPaths = map[string]bool{
+  "/healthz": true,
+  "/readyz": true,
+}
+
+// logRequests logs one line per request with its method, path, status,
+// response size and duration.
+func logRequests(logger *slog.Logger, next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if unloggedPaths[r.URL.Path] {
+      next.ServeHTTP(w, r)
+      return
+    }
+    start := time.Now()
+    rec := &statusRecorder{ResponseWriter: w}
+    next.ServeHTTP(rec, r)
+    if rec.status == 0 {
+      rec.status = http.StatusOK
+    }
+    logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+      slog.String("request_id", RequestIDFromContext(r.Context())),
+      slog.String("method", r.Method),
+      slog.String("path", r.URL.Path),
+      slog.Int("status", rec.status),
+      slog.Int("size", rec.size),
+      slog.Duration("duration", time.Since(start)),
+    )
+  })
+}
+
+// recoverPanics turns a panicking handler into a 500 response instead of
+// a dropped connection, logging the panic with its stack trace.
+func recoverPanics(logger *slog.Logger, next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    defer func() {
+      err := recover()
+      if err == nil {
+        return
+      }
+      if err == http.ErrAbortHandler {
+        panic(err)
+      }
+      logger.Error("handler panicked",
+        slog.String("request_id", RequestIDFromContext(r.Context())),
+        slog.String("method", r.Method),
+        slog.String("path", r.URL.Path),
+        slog.Any("panic", err),
+        slog.String("stack", string(debug.Stack())),
+      )
+      writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+    }()
+    next.ServeHTTP(w, r)
+  })
+}
+
+const readOnlyMethods = "GET, HEAD"
+
+// readOnly rejects anything but GET and HEAD with 405 Method Not Allowed.
+func readOnly(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet && r.Method != http.MethodHead {
+      w.Header().Set("Allow", readOnlyMethods)
+      writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
+
+// limitBody caps request bodies at n bytes. Declared oversized bodies
+// are refused up front; others fail on the read that crosses the limit,
+// which handlers report via writeBodyError. GET and HEAD requests, and
+// any request when n is 0, pass through untouched.
+func limitBody(n int64, next http.Handler) http.Handler {
+  if n <= 0 {
+    return next
+  }
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodGet || r.Method == http.MethodHead {
+      next.ServeHTTP(w, r)
+      return
+    }
+    if r.ContentLength > n {
+      writeError(w, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+      return
+    }
+    r.Body = http.MaxBytesReader(w, r.Body, n)
+    next.ServeHTTP(w, r)
+  })
+}
+
+const (
+  corsAllowMethods = "GET, HEAD, OPTIONS"
+  corsMaxAge = "600"
+)
+
+// cors adds CORS headers for requests from allowedOrigins and answers
+// preflight requests itself. Other origins get no CORS headers, which
+// leaves the browser to block them. With no allowedOrigins, CORS is off
+// and next is returned as it is.
+func cors(allowedOrigins []string, next http.Handler) http.Handler {
+  if len(allowedOrigins) == 0 {
+    return next
+  }
+  anyOrigin := slices.Contains(allowedOrigins, "*")
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    origin := r.Header.Get("Origin")
+    if origin == "" {
+      next.ServeHTTP(w, r)
+      return
+    }
+    h := w.Header()
+    h.Add("Vary", "Origin")
+    allowed := anyOrigin || slices.Contains(allowedOrigins, origin)
+    if allowed {
+      if anyOrigin {
+        h.Set("Access-Control-Allow-Origin", "*")
+      } else {
+        h.Set("Access-Control-Allow-Origin", origin)
+      }
+    }
+
+    if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+      next.ServeHTTP(w, r)
+      return
+    }
+    if allowed {
+      h.Set("Access-Control-Allow-Methods", corsAllowMethods)
+      if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+        h.Set("Access-Control-Allow-Headers", reqHeaders)
+      }
+      h.Set("Access-Control-Max-Age", corsMaxAge)
+    }
+    w.WriteHeader(http.StatusNoContent)
+  })
+}
@@ -0,0 +1,186 @@
+package timeservice
+
+import (
+  "bytes"
+  "encoding/json"
+  "log/slog"
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+)
+
+func TestLogRequests(t *testing.T) {
+  var buf bytes.Buffer
+  h := NewHandler(Config{Greeting: "Hi there", Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+  h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+  if buf.Len() != 0 {
+    t.Errorf("expected /healthz to be excluded from logs, got %q", buf.String())
+  }
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/?format=iso", nil))
+  var entry struct {
+    Msg string
+    RequestID string `json:"request_id"`
+    Method string
+    Path string
+    Status int
+    Size int
+    Duration int64
+  }
+  if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+    t.Fatalf("log line %q: %v", buf.String(), err)
+  }
+  if entry.Method != "GET" || entry.Path != "/" || entry.Status != http.StatusBadRequest {
+    t.Errorf("unexpected log entry %+v", entry)
+  }
+  if entry.RequestID == "" || entry.RequestID != rec.Header().Get("X-Request-ID") {
+    t.Errorf("logged request ID %q, responded with %q", entry.RequestID, rec.Header().Get("X-Request-ID"))
+  }
+  if entry.Size != rec.Body.Len() {
+    t.Errorf("logged size %d, wrote %d bytes", entry.Size, rec.Body.Len())
+  }
+}
+
+func TestRecoverPanics(t *testing.T) {
+  var buf bytes.Buffer
+  logger := slog.New(slog.NewJSONHandler(&buf, nil))
+  h := recoverPanics(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    panic("boom")
+  }))
+
+  srv := httptest.NewServer(h)
+  defer srv.Close()
+  resp, err := http.Get(srv.URL)
+  if err != nil {
+    t.Fatalf("expected a response, got %v", err)
+  }
+  defer resp.Body.Close()
+  if resp.StatusCode != http.StatusInternalServerError {
+    t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+  }
+  if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+    t.Errorf("got Content-Type %q", ct)
+  }
+  var er ErrorResult
+  if err := json.NewDecoder(resp.Body).Decode(&er); err != nil || er.Error == "" {
+    t.Errorf("expected a JSON error body: %v", err)
+  }
+  if !bytes.Contains(buf.Bytes(), []byte("boom")) || !bytes.Contains(buf.Bytes(), []byte("stack")) {
+    t.Errorf("expected the panic and stack to be logged, got %q", buf.String())
+  }
+}
+
+func TestCORS(t *testing.T) {
+  next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.Write([]byte("ok"))
+  })
+  tests := []struct {
+    name string
+    allowed []string
+    method string
+    origin string
+    status int
+    allowOrigin string
+    allowMethods string
+  }{
+    {"simple", []string{"https://a.example"}, "GET", "https://a.example", http.StatusOK, "https://a.example", ""},
+    {"simple wildcard", []string{"*"}, "GET", "https://b.example", http.StatusOK, "*", ""},
+    {"simple other origin", []string{"https://a.example"}, "GET", "https://b.example", http.StatusOK, "", ""},
+    {"same origin", []string{"https://a.example"}, "GET", "", http.StatusOK, "", ""},
+    {"preflight", []string{"https://a.example"}, "OPTIONS", "https://a.example", http.StatusNoContent, "https://a.example", corsAllowMethods},
+    {"preflight other origin", []string{"https://a.example"}, "OPTIONS", "https://b.example", http.StatusNoContent, "", ""},
+    {"preflight disabled", nil, "OPTIONS", "https://a.example", http.StatusOK, "", ""},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest(tt.method, "/", nil)
+    if tt.origin != "" {
+      req.Header.Set("Origin", tt.origin)
+    }
+    if tt.method == "OPTIONS" {
+      req.Header.Set("Access-Control-Request-Method", "GET")
+      req.Header.Set("Access-Control-Request-Headers", "X-Requested-With")
+    }
+    rec := httptest.NewRecorder()
+    cors(tt.allowed, next).ServeHTTP(rec, req)
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.status)
+    }
+    if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+      t.Errorf("%s: got Access-Control-Allow-Origin %q, want %q", tt.name, got, tt.allowOrigin)
+    }
+    if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.allowMethods {
+      t.Errorf("%s: got Access-Control-Allow-Methods %q, want %q", tt.name, got, tt.allowMethods)
+    }
+    if tt.allowMethods != "" && rec.Header().Get("Access-Control-Allow-Headers") != "X-Requested-With" {
+      t.Errorf("%s: expected the requested headers to be echoed", tt.name)
+    }
+  }
+
+  // Without allowed origins, preflights reach the routes like any other
+  // OPTIONS request.
+  req := httptest.NewRequest("OPTIONS", "/v1/time", nil)
+  req.Header.Set("Origin", "https://a.example")
+  req.Header.Set("Access-Control-Request-Method", "GET")
+  rec := httptest.NewRecorder()
+  NewHandler(testConfig(t)).ServeHTTP(rec, req)
+  if vary := rec.Header().Values("Vary"); rec.Code != http.StatusMethodNotAllowed || slices.Contains(vary, "Origin") {
+    t.Errorf("CORS disabled: got status %d and Vary %q, want %d without Origin", rec.Code, vary, http.StatusMethodNotAllowed)
+  }
+}
+
+func TestReadOnly(t *testing.T) {
+  h := NewHandler(testConfig(t))
+  for _, path := range []string{"/", "/v1/time", "/v1/now", "/healthz"} {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("POST", path, nil))
+    if rec.Code != http.StatusMethodNotAllowed {
+      t.Errorf("POST %s: got status %d, want %d", path, rec.Code, http.StatusMethodNotAllowed)
+    }
+    if got := rec.Header().Get("Allow"); got != "GET, HEAD" {
+      t.Errorf("POST %s: got Allow %q", path, got)
+    }
+    var er ErrorResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &er); err != nil || er.Error == "" {
+      t.Errorf("POST %s: expected JSON error body, got %q", path, rec.Body.String())
+    }
+  }
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("HEAD", "/v1/time", nil))
+  if rec.Code != http.StatusOK {
+    t.Errorf("HEAD: got status %d, want %d", rec.Code, http.StatusOK)
+  }
+}
+
+func TestLimitBody(t *testing.T) {
+  t.Setenv("MAX_BODY_BYTES", "16")
+  cfg := testConfig(t)
+  cfg.APIKey = "s3cret"
+  h := NewHandler(cfg)
+  body := `{"Enabled":true,"padding":"` + strings.Repeat("x", 32) + `"}`
+
+  post := func(req *http.Request) int {
+    req.Header.Set("Authorization", "Bearer s3cret")
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec.Code
+  }
+  if code := post(httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(body))); code != http.StatusRequestEntityTooLarge {
+    t.Errorf("declared length: got status %d, want %d", code, http.StatusRequestEntityTooLarge)
+  }
+  req := httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(body))
+  req.ContentLength = -1
+  if code := post(req); code != http.StatusRequestEntityTooLarge {
+    t.Errorf("unknown length: got status %d, want %d", code, http.StatusRequestEntityTooLarge)
+  }
+  if cfg.State.InMaintenance() {
+    t.Error("oversized body toggled maintenance mode")
+  }
+  if code := post(httptest.NewRequest("GET", "/", strings.NewReader(body))); code != http.StatusOK {
+    t.Errorf("GET: got status %d, want %d", code, http.StatusOK)
+  }
+}
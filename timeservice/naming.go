@@ -0,0 +1,137 @@
+package timeservice
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "io"
+  "net/http"
+  "strings"
+  "unicode"
+)
+
+// The JSON key styles Config.JSONNaming selects between. Response types
+// are tagged in camelCase; snake_case is derived from the tags.
+const (
+  NamingCamelCase = "camelCase"
+  NamingSnakeCase = "snake_case"
+)
+
+type snakeCaseKey struct{}
+
+// snakeCase reports whether JSON responses to the request use snake_case
+// keys.
+func snakeCase(ctx context.Context) bool {
+  on, _ := ctx.Value(snakeCaseKey{}).(bool)
+  return on
+}
+
+// withSnakeCase switches the requests it serves to snake_case keys.
+func withSnakeCase(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ctx := context.WithValue(r.Context(), snakeCaseKey{}, true)
+    next.ServeHTTP(w, r.WithContext(ctx))
+  })
+}
+
+// marshalNamed encodes v on one line with the keys the request's naming
+// calls for. Streams use it directly, as their events can be neither
+// enveloped nor indented.
+func marshalNamed(r *http.Request, v any) ([]byte, error) {
+  js, err := json.Marshal(v)
+  if err != nil || !snakeCase(r.Context()) {
+    return js, err
+  }
+  return renameKeys(js, toSnakeCase, dataKeys)
+}
+
+// dataKeys are the fields whose values are objects keyed by client data
+// rather than by field names: EchoResult's Query and Headers. Their keys
+// are reported as received, so renameKeys leaves them alone.
+var dataKeys = map[string]bool{
+  "query": true,
+  "headers": true,
+}
+
+// toSnakeCase converts a camelCase key, so "formattedTime" becomes
+// "formatted_time" and "requestId" becomes "request_id".
+func toSnakeCase(s string) string {
+  var b strings.Builder
+  for i, c := range s {
+    if unicode.IsUpper(c) {
+      if i > 0 {
+        b.WriteByte('_')
+      }
+      c = unicode.ToLower(c)
+    }
+    b.WriteRune(c)
+  }
+  return b.String()
+}
+
+// renameKeys rewrites the object keys in the JSON document js with
+// rename, keeping keys in their original order. Everything within the
+// value of a key in keep is left as it is.
+func renameKeys(js []byte, rename func(string) string, keep map[string]bool) ([]byte, error) {
+  dec := json.NewDecoder(bytes.NewReader(js))
+  dec.UseNumber()
+
+  // For each open container: whether it is an object, whether its keys
+  // are kept, how many tokens (keys and values alike) it holds so far
+  // and, for objects, the last key read.
+  type container struct {
+    object bool
+    kept bool
+    n int
+    key string
+  }
+  var stack []container
+  var buf bytes.Buffer
+  for {
+    tok, err := dec.Token()
+    if errors.Is(err, io.EOF) {
+      return buf.Bytes(), nil
+    }
+    if err != nil {
+      return nil, err
+    }
+    if tok == json.Delim('}') || tok == json.Delim(']') {
+      stack = stack[:len(stack)-1]
+      buf.WriteByte(byte(tok.(json.Delim)))
+      continue
+    }
+
+    kept := false
+    if len(stack) > 0 {
+      top := &stack[len(stack)-1]
+      kept = top.kept
+      isKey := top.object && top.n%2 == 0
+      switch {
+      case top.object && !isKey:
+        buf.WriteByte(':')
+        kept = kept || keep[top.key]
+      case top.n > 0:
+        buf.WriteByte(',')
+      }
+      if isKey {
+        top.key = tok.(string)
+        if !top.kept {
+          tok = rename(top.key)
+        }
+      }
+      top.n++
+    }
+
+    if d, ok := tok.(json.Delim); ok {
+      buf.WriteByte(byte(d))
+      stack = append(stack, container{object: d == '{', kept: kept})
+      continue
+    }
+    b, err := json.Marshal(tok)
+    if err != nil {
+      return nil, err
+    }
+    buf.Write(b)
+  }
+}
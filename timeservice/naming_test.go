@@ -0,0 +1,71 @@
+package timeservice
+
+import (
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestToSnakeCase(t *testing.T) {
+  tests := map[string]string{
+    "formattedTime": "formatted_time",
+    "requestId": "request_id",
+    "unixMilli": "unix_milli",
+    "rfc822z": "rfc822z",
+    "utc": "utc",
+  }
+  for in, want := range tests {
+    if got := toSnakeCase(in); got != want {
+      t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+    }
+  }
+}
+
+func TestRenameKeys(t *testing.T) {
+  in := `{"zKey":[{"innerKey":1.50},"notAKey",null],"aKey":{"deepKey":"a\"b<"},"dataKey":{"X-Header":["v"],"clientTime":{"innerKey":[{"deepKey":1}]}},"last":true}`
+  want := `{"z_key":[{"inner_key":1.50},"notAKey",null],"a_key":{"deep_key":"a\"b\u003c"},"data_key":{"X-Header":["v"],"clientTime":{"innerKey":[{"deepKey":1}]}},"last":true}`
+  got, err := renameKeys([]byte(in), toSnakeCase, map[string]bool{"dataKey": true})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(got) != want {
+    t.Errorf("got %s, want %s", got, want)
+  }
+}
+
+func TestJSONNaming(t *testing.T) {
+  cfg := testConfig(t)
+  now := time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC)
+  cfg.Clock = fixedClock(now)
+  cfg.StartTime = now
+  tests := []struct {
+    naming string
+    path string
+    want string
+  }{
+    {NamingCamelCase, "/", `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {NamingSnakeCase, "/", `{"formatted_time":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {NamingCamelCase, "/v1/skew?client_time=2016-09-23T10:39:00Z", `{"serverTime":"2016-09-23T10:39:00Z","clientTime":"2016-09-23T10:39:00Z","skewMillis":0}`},
+    {NamingSnakeCase, "/v1/skew?client_time=2016-09-23T10:39:00Z", `{"server_time":"2016-09-23T10:39:00Z","client_time":"2016-09-23T10:39:00Z","skew_millis":0}`},
+    {NamingSnakeCase, "/?pretty=true", "{\n  \"formatted_time\": \"23 Sep 16 10:39 +0000\",\n  \"greeting\": \"Hi there\"\n}"},
+  }
+  for _, tt := range tests {
+    cfg.JSONNaming = tt.naming
+    rec := httptest.NewRecorder()
+    NewHandler(cfg).ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+    if got := rec.Body.String(); got != tt.want {
+      t.Errorf("%s %s: got %s, want %s", tt.naming, tt.path, got, tt.want)
+    }
+  }
+
+  cfg.JSONNaming = NamingSnakeCase
+  cfg.Envelope = true
+  req := httptest.NewRequest("GET", "/v1/uptime", nil)
+  req.Header.Set("X-Request-ID", "abc123")
+  rec := httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, req)
+  want := `{"data":{"start_time":"2016-09-23T10:39:00Z","current_time":"2016-09-23T10:39:00Z","uptime_seconds":0,"uptime":"0s"},"meta":{"request_id":"abc123","server_time":"2016-09-23T10:39:00Z"}}`
+  if got := rec.Body.String(); got != want {
+    t.Errorf("enveloped: got %s, want %s", got, want)
+  }
+}
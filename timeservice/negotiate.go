@@ -0,0 +1,73 @@
+package timeservice
+
+import (
+  "sort"
+  "strconv"
+  "strings"
+)
+
+const (
+  contentTypeJSON = "application/json"
+  contentTypeXML = "application/xml"
+  contentTypeText = "text/plain"
+)
+
+// weighted is one element of a header list such as Accept, with its
+// quality value.
+type weighted struct {
+  value string
+  q float64
+}
+
+// parseWeighted splits a comma-separated header into its values ordered
+// by descending quality. Values without a q parameter default to 1 and
+// ties keep header order. Entries with q=0 are dropped.
+func parseWeighted(header string) []weighted {
+  var values []weighted
+  for _, part := range strings.Split(header, ",") {
+    params := strings.Split(part, ";")
+    value := strings.ToLower(strings.TrimSpace(params[0]))
+    if value == "" {
+      continue
+    }
+    q := 1.0
+    for _, p := range params[1:] {
+      k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+      if !ok || strings.TrimSpace(k) != "q" {
+        continue
+      }
+      if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+        q = f
+      }
+    }
+    if q <= 0 {
+      continue
+    }
+    values = append(values, weighted{value, q})
+  }
+  sort.SliceStable(values, func(i, j int) bool { return values[i].q > values[j].q })
+  return values
+}
+
+// negotiateContentType returns the offer that best matches the Accept
+// header. The first offer wins when the header is empty or a wildcard;
+// ok is false when nothing acceptable can be served.
+func negotiateContentType(accept string, offers []string) (string, bool) {
+  if strings.TrimSpace(accept) == "" {
+    return offers[0], true
+  }
+  for _, mr := range parseWeighted(accept) {
+    if mr.value == "*/*" {
+      return offers[0], true
+    }
+    for _, offer := range offers {
+      if mr.value == offer {
+        return offer, true
+      }
+      if prefix, ok := strings.CutSuffix(mr.value, "/*"); ok && strings.HasPrefix(offer, prefix+"/") {
+        return offer, true
+      }
+    }
+  }
+  return "", false
+}
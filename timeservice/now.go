@@ -0,0 +1,73 @@
+package timeservice
+
+import (
+  "net/http"
+  "time"
+)
+
+type TimeInfo struct {
+  UTC time.Time `json:"utc"`
+  Local time.Time `json:"local"`
+  Unix int64 `json:"unix"`
+  UnixNano int64 `json:"unixNano"`
+  Timezone string `json:"timezone"`
+  Offset int `json:"offset"`
+
+  // Formats renders Local in every supported format.
+  Formats MultiFormatTime `json:"formats"`
+
+  // ClockOffset is the correction, in seconds, that an NTP-backed clock
+  // applies to the system clock. It is omitted when there is none.
+  ClockOffset float64 `json:"clockOffset,omitempty"`
+}
+
+// MultiFormatTime is one instant in each of the formats accepted by the
+// format parameter.
+type MultiFormatTime struct {
+  RFC3339 string `json:"rfc3339"`
+  RFC822Z string `json:"rfc822z"`
+  Unix int64 `json:"unix"`
+  UnixMilli int64 `json:"unixMilli"`
+}
+
+func newMultiFormatTime(t time.Time) MultiFormatTime {
+  return MultiFormatTime{
+    RFC3339: timeFormats["rfc3339"](t),
+    RFC822Z: timeFormats["rfc822z"](t),
+    Unix: t.Unix(),
+    UnixMilli: t.UnixMilli(),
+  }
+}
+
+func newTimeInfo(t time.Time, loc *time.Location) TimeInfo {
+  local := t.In(loc)
+  _, offset := local.Zone()
+  return TimeInfo{
+    UTC: t.UTC(),
+    Local: local,
+    Unix: t.Unix(),
+    UnixNano: t.UnixNano(),
+    Timezone: loc.String(),
+    Offset: offset,
+    Formats: newMultiFormatTime(local),
+  }
+}
+
+// NowHandler describes the current instant in detail, with Local in the
+// zone given by tz.
+func NowHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    loc, err := requestLocation(r)
+    if err != nil {
+      writeLocationError(w, err)
+      return
+    }
+    info := newTimeInfo(cfg.Clock.Now(), loc)
+    if oc, ok := cfg.Clock.(offsetClock); ok {
+      info.ClockOffset = oc.Offset().Seconds()
+    }
+    writeJSON(w, r, info)
+  }
+}
@@ -0,0 +1,77 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestNow(t *testing.T) {
+  cfg := testConfig(t)
+  now := time.Date(2016, time.September, 23, 10, 39, 0, 500, time.UTC)
+  cfg.Clock = fixedClock(now)
+
+  rec := httptest.NewRecorder()
+  NowHandler(cfg)(rec, httptest.NewRequest("GET", "/now?tz=Asia/Tokyo", nil))
+  if rec.Code != http.StatusOK {
+    t.Fatalf("got status %d", rec.Code)
+  }
+  var info TimeInfo
+  if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+    t.Fatal(err)
+  }
+  if !info.UTC.Equal(now) || !info.Local.Equal(now) {
+    t.Errorf("got UTC %v and Local %v, want %v", info.UTC, info.Local, now)
+  }
+  if got := info.Local.Format(time.RFC3339); got != "2016-09-23T19:39:00+09:00" {
+    t.Errorf("got Local %q", got)
+  }
+  if info.Unix != 1474627140 || info.UnixNano != 1474627140000000500 {
+    t.Errorf("got Unix %d and UnixNano %d", info.Unix, info.UnixNano)
+  }
+  if info.Timezone != "Asia/Tokyo" || info.Offset != 9*3600 {
+    t.Errorf("got Timezone %q and Offset %d", info.Timezone, info.Offset)
+  }
+
+  rec = httptest.NewRecorder()
+  NowHandler(cfg)(rec, httptest.NewRequest("GET", "/now?tz=Atlantis", nil))
+  if rec.Code != http.StatusBadRequest {
+    t.Errorf("unknown tz: got status %d", rec.Code)
+  }
+}
+
+func TestNowFormats(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 250e6, time.UTC))
+
+  rec := httptest.NewRecorder()
+  NowHandler(cfg)(rec, httptest.NewRequest("GET", "/now?tz=Europe/Lisbon", nil))
+  var info TimeInfo
+  if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+    t.Fatal(err)
+  }
+  f := info.Formats
+  want := MultiFormatTime{
+    RFC3339: "2016-09-23T11:39:00+01:00",
+    RFC822Z: "23 Sep 16 11:39 +0100",
+    Unix: 1474627140,
+    UnixMilli: 1474627140250,
+  }
+  if f != want {
+    t.Errorf("got %+v, want %+v", f, want)
+  }
+
+  // Every rendering must denote the same instant as the others.
+  parsed, err := time.Parse(time.RFC3339, f.RFC3339)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if parsed.Unix() != f.Unix || f.UnixMilli/1000 != f.Unix || parsed.Unix() != info.Unix {
+    t.Errorf("inconsistent formats %+v", f)
+  }
+  if got := parsed.Format(time.RFC822Z); got != f.RFC822Z {
+    t.Errorf("rfc822z %q disagrees with rfc3339 %q", f.RFC822Z, f.RFC3339)
+  }
+}
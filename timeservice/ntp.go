@@ -0,0 +1,44 @@
+package timeservice
+
+import (
+  "fmt"
+  "time"
+
+  "github.com/beevik/ntp"
+)
+
+const ntpTimeout = 5 * time.Second
+
+// NTPClock is the system clock corrected by an offset measured against
+// an NTP server.
+type NTPClock struct {
+  offset time.Duration
+}
+
+// NewNTPClock queries server once and caches the measured offset.
+func NewNTPClock(server string) (*NTPClock, error) {
+  resp, err := ntp.QueryWithOptions(server, ntp.QueryOptions{Timeout: ntpTimeout})
+  if err != nil {
+    return nil, fmt.Errorf("querying NTP server %s: %w", server, err)
+  }
+  if err := resp.Validate(); err != nil {
+    return nil, fmt.Errorf("invalid response from NTP server %s: %w", server, err)
+  }
+  return &NTPClock{offset: resp.ClockOffset}, nil
+}
+
+// Now returns the corrected time. Adding the offset keeps the monotonic
+// reading from time.Now.
+func (c *NTPClock) Now() time.Time {
+  return time.Now().Add(c.offset)
+}
+
+// Offset is how far the system clock is behind the NTP server.
+func (c *NTPClock) Offset() time.Duration {
+  return c.offset
+}
+
+// offsetClock is implemented by clocks that correct the system time.
+type offsetClock interface {
+  Offset() time.Duration
+}
@@ -0,0 +1,33 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestNTPClock(t *testing.T) {
+  c := &NTPClock{offset: 90 * time.Second}
+  if d := c.Now().Sub(time.Now()); d < 89*time.Second || d > 91*time.Second {
+    t.Errorf("Now is %v ahead of the system clock, want about 90s", d)
+  }
+
+  cfg := testConfig(t)
+  cfg.Clock = c
+  rec := httptest.NewRecorder()
+  NowHandler(cfg)(rec, httptest.NewRequest("GET", "/v1/now", nil))
+  var info TimeInfo
+  if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+    t.Fatal(err)
+  }
+  if info.ClockOffset != 90 {
+    t.Errorf("got ClockOffset %v, want 90", info.ClockOffset)
+  }
+}
+
+func TestNewNTPClockUnreachable(t *testing.T) {
+  if _, err := NewNTPClock("ntp.invalid"); err == nil {
+    t.Error("expected an error for an unresolvable server")
+  }
+}
@@ -0,0 +1,90 @@
+package timeservice
+
+import (
+  "math"
+  "net/http"
+  "strconv"
+  "sync"
+  "time"
+
+  "golang.org/x/time/rate"
+)
+
+const limiterIdleTimeout = 5 * time.Minute
+
+type clientLimiter struct {
+  limiter *rate.Limiter
+  lastSeen time.Time
+}
+
+// rateLimiter keeps a token bucket per client IP. Buckets idle for
+// longer than idleTimeout are swept at most once per idleTimeout, from
+// the request path, so no background goroutine is needed.
+type rateLimiter struct {
+  limit rate.Limit
+  burst int
+  idleTimeout time.Duration
+
+  mu sync.Mutex
+  clients map[string]*clientLimiter
+  lastSweep time.Time
+}
+
+func newRateLimiter(limit rate.Limit, burst int) *rateLimiter {
+  return &rateLimiter{
+    limit: limit,
+    burst: burst,
+    idleTimeout: limiterIdleTimeout,
+    clients: make(map[string]*clientLimiter),
+  }
+}
+
+// allow takes a token for key, or reports how long until one is free.
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+  rl.mu.Lock()
+  defer rl.mu.Unlock()
+
+  if now.Sub(rl.lastSweep) >= rl.idleTimeout {
+    for k, c := range rl.clients {
+      if now.Sub(c.lastSeen) >= rl.idleTimeout {
+        delete(rl.clients, k)
+      }
+    }
+    rl.lastSweep = now
+  }
+
+  c, ok := rl.clients[key]
+  if !ok {
+    c = &clientLimiter{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+    rl.clients[key] = c
+  }
+  c.lastSeen = now
+
+  res := c.limiter.ReserveN(now, 1)
+  if !res.OK() {
+    return false, rl.idleTimeout
+  }
+  if delay := res.DelayFrom(now); delay > 0 {
+    res.CancelAt(now)
+    return false, delay
+  }
+  return true, 0
+}
+
+// middleware rejects clients over their rate with 429 Too Many Requests.
+// Liveness and readiness probes are never limited.
+func (rl *rateLimiter) middleware(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if probePaths[r.URL.Path] {
+      next.ServeHTTP(w, r)
+      return
+    }
+    ok, retryAfter := rl.allow(clientIP(r), time.Now())
+    if !ok {
+      w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+      writeError(w, http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests))
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
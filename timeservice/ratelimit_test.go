@@ -0,0 +1,55 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strconv"
+  "testing"
+  "time"
+)
+
+func TestRateLimit(t *testing.T) {
+  rl := newRateLimiter(1, 2)
+  h := rl.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+  request := func(remoteAddr, path string) *httptest.ResponseRecorder {
+    req := httptest.NewRequest("GET", path, nil)
+    req.RemoteAddr = remoteAddr
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    return rec
+  }
+
+  for i := 0; i < 2; i++ {
+    if rec := request("192.0.2.1:1234", "/"); rec.Code != http.StatusOK {
+      t.Fatalf("request %d within burst: got status %d", i, rec.Code)
+    }
+  }
+  rec := request("192.0.2.1:1234", "/")
+  if rec.Code != http.StatusTooManyRequests {
+    t.Fatalf("over the limit: got status %d, want 429", rec.Code)
+  }
+  if s, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || s < 1 {
+    t.Errorf("got Retry-After %q", rec.Header().Get("Retry-After"))
+  }
+
+  if rec := request("192.0.2.2:1234", "/"); rec.Code != http.StatusOK {
+    t.Errorf("other client: got status %d", rec.Code)
+  }
+  if rec := request("192.0.2.1:1234", "/healthz"); rec.Code != http.StatusOK {
+    t.Errorf("/healthz: got status %d", rec.Code)
+  }
+}
+
+func TestRateLimitEvictsIdle(t *testing.T) {
+  rl := newRateLimiter(1, 1)
+  start := time.Now()
+  rl.allow("192.0.2.1", start)
+  rl.allow("192.0.2.2", start.Add(rl.idleTimeout/2))
+  rl.allow("192.0.2.2", start.Add(rl.idleTimeout))
+  if _, ok := rl.clients["192.0.2.1"]; ok {
+    t.Error("expected the idle limiter to be evicted")
+  }
+  if _, ok := rl.clients["192.0.2.2"]; !ok {
+    t.Error("expected the active limiter to be kept")
+  }
+}
@@ -0,0 +1,50 @@
+package timeservice
+
+import (
+  "context"
+  "net/http"
+
+  "github.com/google/uuid"
+)
+
+const (
+  requestIDHeader = "X-Request-ID"
+  maxRequestIDLen = 128
+)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID assigned to the request by the
+// request-ID middleware, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+  id, _ := ctx.Value(requestIDKey{}).(string)
+  return id
+}
+
+// validRequestID accepts short, printable ASCII IDs, so a client can't
+// inject arbitrary bytes into our logs and headers.
+func validRequestID(id string) bool {
+  if id == "" || len(id) > maxRequestIDLen {
+    return false
+  }
+  for i := 0; i < len(id); i++ {
+    if id[i] < 0x21 || id[i] > 0x7e {
+      return false
+    }
+  }
+  return true
+}
+
+// withRequestID keeps the caller's X-Request-ID, or generates a UUID,
+// and echoes it on the response.
+func withRequestID(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    id := r.Header.Get(requestIDHeader)
+    if !validRequestID(id) {
+      id = uuid.NewString()
+    }
+    w.Header().Set(requestIDHeader, id)
+    ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+    next.ServeHTTP(w, r.WithContext(ctx))
+  })
+}
@@ -0,0 +1,43 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+
+  "github.com/google/uuid"
+)
+
+func TestWithRequestID(t *testing.T) {
+  var seen string
+  h := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    seen = RequestIDFromContext(r.Context())
+  }))
+
+  req := httptest.NewRequest("GET", "/", nil)
+  req.Header.Set("X-Request-ID", "abc-123")
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, req)
+  if seen != "abc-123" || rec.Header().Get("X-Request-ID") != "abc-123" {
+    t.Errorf("incoming ID not preserved: context %q, header %q", seen, rec.Header().Get("X-Request-ID"))
+  }
+
+  for _, incoming := range []string{"", "bad id\n"} {
+    req := httptest.NewRequest("GET", "/", nil)
+    if incoming != "" {
+      req.Header.Set("X-Request-ID", incoming)
+    }
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    if _, err := uuid.Parse(seen); err != nil {
+      t.Errorf("incoming %q: expected a generated UUID, got %q", incoming, seen)
+    }
+    if rec.Header().Get("X-Request-ID") != seen {
+      t.Errorf("incoming %q: header %q doesn't match context %q", incoming, rec.Header().Get("X-Request-ID"), seen)
+    }
+  }
+
+  if id := RequestIDFromContext(req.Context()); id != "" {
+    t.Errorf("got %q outside the middleware", id)
+  }
+}
@@ -0,0 +1,77 @@
+package timeservice
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "net/http"
+)
+
+// marshalJSON encodes v, indented with two spaces when the request asks
+// for ?pretty=true, wrapped in an Envelope when that is enabled and with
+// snake_case keys when Config.JSONNaming asks for them.
+func marshalJSON(r *http.Request, v any) ([]byte, error) {
+  if clock, ok := envelopeClock(r.Context()); ok {
+    v = Envelope{
+      Data: v,
+      Meta: EnvelopeMeta{
+        RequestID: RequestIDFromContext(r.Context()),
+        ServerTime: clock.Now().UTC(),
+      },
+    }
+  }
+  js, err := marshalNamed(r, v)
+  if err != nil {
+    return nil, err
+  }
+  if r.URL.Query().Get("pretty") == "true" {
+    var buf bytes.Buffer
+    if err := json.Indent(&buf, js, "", "  "); err != nil {
+      return nil, err
+    }
+    return buf.Bytes(), nil
+  }
+  return js, nil
+}
+
+// setLiveHeaders marks a response as reporting the current time: it
+// must never be cached, and its Date comes from clock, the same clock
+// as the body, instead of the system clock net/http would use.
+func setLiveHeaders(w http.ResponseWriter, clock Clock) {
+  w.Header().Set("Cache-Control", "no-store")
+  w.Header().Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+}
+
+// writeJSON writes v as a JSON response body.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+  js, err := marshalJSON(r, v)
+
+  if err != nil {
+    writeError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  w.Header().Set("Content-Type", contentTypeJSON)
+  w.Write(js)
+}
+
+// writeBodyError reports a failure to read the request body: 413 when
+// it exceeded the limit set by limitBody, 400 otherwise.
+func writeBodyError(w http.ResponseWriter, err error) {
+  var tooLarge *http.MaxBytesError
+  if errors.As(err, &tooLarge) {
+    writeError(w, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+    return
+  }
+  writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+}
+
+// writeError writes the ErrorResult every failed request gets, so
+// clients have a single error format to parse.
+func writeError(w http.ResponseWriter, status int, msg string) {
+  // An ErrorResult always encodes.
+  js, _ := json.Marshal(ErrorResult{msg, status})
+
+  w.Header().Set("Content-Type", contentTypeJSON)
+  w.WriteHeader(status)
+  w.Write(js)
+}
@@ -0,0 +1,92 @@
+package timeservice
+
+import (
+  "io"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestPrettyJSON(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  tests := []struct {
+    query string
+    want string
+  }{
+    {"", `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {"?pretty=false", `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {"?pretty=true", "{\n  \"formattedTime\": \"23 Sep 16 10:39 +0000\",\n  \"greeting\": \"Hi there\"\n}"},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    TimeHandler(cfg)(rec, httptest.NewRequest("GET", "/"+tt.query, nil))
+    if rec.Body.String() != tt.want {
+      t.Errorf("%q: got %q, want %q", tt.query, rec.Body.String(), tt.want)
+    }
+  }
+
+  rec := httptest.NewRecorder()
+  DurationHandler(rec, httptest.NewRequest("GET", "/duration?start=2016-09-23T10:00:00Z&end=2016-09-23T10:00:01Z&pretty=true", nil))
+  want := "{\n  \"seconds\": 1,\n  \"duration\": \"1s\"\n}"
+  if rec.Body.String() != want {
+    t.Errorf("/duration: got %q, want %q", rec.Body.String(), want)
+  }
+}
+
+func TestErrorShape(t *testing.T) {
+  rec := httptest.NewRecorder()
+  TimeHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/?tz=Mars/Olympus", nil))
+  if rec.Code != http.StatusBadRequest {
+    t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+  }
+  if got := rec.Header().Get("Content-Type"); got != "application/json" {
+    t.Errorf("got Content-Type %q", got)
+  }
+  want := `{"error":"unknown time zone \"Mars/Olympus\"","status":400}`
+  if got := rec.Body.String(); got != want {
+    t.Errorf("got body %q, want %q", got, want)
+  }
+}
+
+func TestLiveHeaders(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  h := NewHandler(cfg)
+  for _, path := range []string{"/", "/time.txt", "/v1/now", "/v1/uptime"} {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+    if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+      t.Errorf("%s: got Cache-Control %q", path, got)
+    }
+    if got := rec.Header().Get("Date"); got != "Fri, 23 Sep 2016 10:39:00 GMT" {
+      t.Errorf("%s: got Date %q, want the injected clock's time", path, got)
+    }
+  }
+
+  // With the real clock, Date and the body must agree to the second.
+  srv := httptest.NewServer(NewHandler(testConfig(t)))
+  defer srv.Close()
+  resp, err := http.Get(srv.URL + "/time.txt?format=rfc3339")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer resp.Body.Close()
+  body, err := io.ReadAll(resp.Body)
+  if err != nil {
+    t.Fatal(err)
+  }
+  bodyTime, err := time.Parse(time.RFC3339, strings.TrimSpace(string(body)))
+  if err != nil {
+    t.Fatal(err)
+  }
+  date, err := http.ParseTime(resp.Header.Get("Date"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  if d := date.Sub(bodyTime); d < -time.Second || d > time.Second {
+    t.Errorf("Date %v is %v away from the body's %v", date, d, bodyTime)
+  }
+}
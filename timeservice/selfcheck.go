@@ -0,0 +1,46 @@
+package timeservice
+
+import (
+  "errors"
+  "fmt"
+  "slices"
+  "time"
+)
+
+// SelfCheck makes sure the service can do its job before it starts
+// listening: the clock must tell the time and every format must render
+// it. Each check is logged; the first failure is returned, so a broken
+// image fails at boot rather than on its first request. A missing time
+// zone database isn't a failure, as UTC still works; main warns of it.
+func SelfCheck(cfg Config) error {
+  cfg = cfg.withDefaults()
+
+  now := cfg.Clock.Now()
+  if now.IsZero() {
+    return selfCheckFailed(cfg, "clock", errors.New("clock returned the zero time"))
+  }
+  cfg.Logger.Info("self-check passed", "check", "clock", "now", now)
+
+  formats := make([]string, 0, len(timeFormats))
+  for format := range timeFormats {
+    formats = append(formats, format)
+  }
+  slices.Sort(formats)
+  for _, format := range formats {
+    s, err := cfg.currentTime(format, time.UTC)
+    if err == nil && s == "" {
+      err = errors.New("rendered an empty string")
+    }
+    if err != nil {
+      return selfCheckFailed(cfg, "format "+format, err)
+    }
+  }
+  cfg.Logger.Info("self-check passed", "check", "formats", "formats", formats)
+  return nil
+}
+
+func selfCheckFailed(cfg Config, check string, err error) error {
+  cfg.Logger.Error("self-check failed", "check", check, "err", err)
+  return fmt.Errorf("self-check %s: %w", check, err)
+}
+
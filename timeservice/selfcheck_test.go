@@ -0,0 +1,37 @@
+package timeservice
+
+import (
+  "bytes"
+  "log/slog"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestSelfCheck(t *testing.T) {
+  var buf bytes.Buffer
+  cfg := testConfig(t)
+  cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+  if err := SelfCheck(cfg); err != nil {
+    t.Fatalf("healthy config: %v", err)
+  }
+  if n := strings.Count(buf.String(), "self-check passed"); n != 2 {
+    t.Errorf("logged %d passing checks, want 2: %s", n, buf.String())
+  }
+}
+
+func TestSelfCheckBroken(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Logger = slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+  cfg.Clock = fixedClock(time.Time{})
+  if err := SelfCheck(cfg); err == nil || !strings.Contains(err.Error(), "clock") {
+    t.Errorf("zero clock: got %v", err)
+  }
+}
+
+func TestSelfCheckWithoutTZData(t *testing.T) {
+  withoutTZData(t)
+  if err := SelfCheck(testConfig(t)); err != nil {
+    t.Errorf("without tzdata: got %v, want the UTC checks to pass", err)
+  }
+}
@@ -0,0 +1,31 @@
+package timeservice
+
+import (
+  "net/http"
+
+  "golang.org/x/net/http2"
+  "golang.org/x/net/http2/h2c"
+)
+
+// NewServer returns an http.Server for cfg's address and timeouts that
+// serves NewHandler(cfg), over h2c as well as HTTP/1.1 when cfg.H2C is
+// set.
+func NewServer(cfg Config) *http.Server {
+  server := &http.Server{
+    Addr: cfg.Addr,
+    Handler: NewHandler(cfg),
+    ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+    ReadTimeout: cfg.ReadTimeout,
+    WriteTimeout: cfg.WriteTimeout,
+    IdleTimeout: cfg.IdleTimeout,
+  }
+  if cfg.H2C {
+    h2s := &http2.Server{IdleTimeout: cfg.IdleTimeout}
+    // h2c connections are hijacked from server; configuring h2s on it
+    // lets server.Shutdown send them GOAWAY too. That can only fail for
+    // a TLSConfig with unusable cipher suites, and server has none.
+    http2.ConfigureServer(server, h2s)
+    server.Handler = h2c.NewHandler(server.Handler, h2s)
+  }
+  return server
+}
@@ -0,0 +1,43 @@
+package timeservice
+
+import (
+  "context"
+  "crypto/tls"
+  "net"
+  "net/http"
+  "testing"
+
+  "golang.org/x/net/http2"
+)
+
+func TestNewServerH2C(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.H2C = true
+  server := NewServer(cfg)
+  ln, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  go server.Serve(ln)
+  defer server.Shutdown(context.Background())
+  url := "http://" + ln.Addr().String() + "/"
+
+  h2cClient := &http.Client{Transport: &http2.Transport{
+    AllowHTTP: true,
+    DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+      var d net.Dialer
+      return d.DialContext(ctx, network, addr)
+    },
+  }}
+  clients := map[string]*http.Client{"HTTP/2.0": h2cClient, "HTTP/1.1": http.DefaultClient}
+  for proto, client := range clients {
+    resp, err := client.Get(url)
+    if err != nil {
+      t.Fatalf("%s: %v", proto, err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK || resp.Proto != proto {
+      t.Errorf("%s: got %s %d", proto, resp.Proto, resp.StatusCode)
+    }
+  }
+}
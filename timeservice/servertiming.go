@@ -0,0 +1,62 @@
+package timeservice
+
+import (
+  "bufio"
+  "fmt"
+  "net"
+  "net/http"
+  "time"
+)
+
+// serverTimingWriter adds a Server-Timing header with the time spent so
+// far just before the response headers are written, since headers can't
+// change afterwards.
+type serverTimingWriter struct {
+  http.ResponseWriter
+  start time.Time
+  wroteHeader bool
+}
+
+func (w *serverTimingWriter) WriteHeader(status int) {
+  if !w.wroteHeader {
+    w.wroteHeader = true
+    ms := float64(time.Since(w.start)) / float64(time.Millisecond)
+    w.Header().Set("Server-Timing", fmt.Sprintf("app;dur=%.3f", ms))
+  }
+  w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+  if !w.wroteHeader {
+    w.WriteHeader(http.StatusOK)
+  }
+  return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *serverTimingWriter) Unwrap() http.ResponseWriter {
+  return w.ResponseWriter
+}
+
+// Hijack supports WebSocket upgrades, as on statusRecorder. A hijacked
+// connection has no headers left to add to.
+func (w *serverTimingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+  conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+  if err == nil {
+    w.wroteHeader = true
+  }
+  return conn, rw, err
+}
+
+// serverTiming reports how long the handler took to produce its response
+// headers, so clients can tell server time from network time. Handlers
+// that write nothing, as for HEAD, get the header when they return.
+func serverTiming(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    tw := &serverTimingWriter{ResponseWriter: w, start: time.Now()}
+    next.ServeHTTP(tw, r)
+    if !tw.wroteHeader {
+      tw.WriteHeader(http.StatusOK)
+    }
+  })
+}
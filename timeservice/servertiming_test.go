@@ -0,0 +1,42 @@
+package timeservice
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "regexp"
+  "testing"
+)
+
+func TestServerTiming(t *testing.T) {
+  h := NewHandler(testConfig(t))
+  pattern := regexp.MustCompile(`^app;dur=\d+\.\d{3}$`)
+  for _, path := range []string{"/", "/v1/unknown", "/healthz"} {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+    if got := rec.Header().Get("Server-Timing"); !pattern.MatchString(got) {
+      t.Errorf("%s: got Server-Timing %q", path, got)
+    }
+  }
+
+  // The header must be in place before WriteHeader sends it.
+  rec := httptest.NewRecorder()
+  serverTiming(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusAccepted)
+    w.Header().Set("Server-Timing", "late")
+  })).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+  if got := rec.Result().Header.Get("Server-Timing"); !pattern.MatchString(got) {
+    t.Errorf("explicit WriteHeader: got Server-Timing %q", got)
+  }
+
+  // Nor may a handler that writes nothing go without it.
+  cfg := testConfig(t)
+  cfg.HandlerTimeout = 0
+  h = NewHandler(cfg)
+  for _, path := range []string{"/", "/time.txt"} {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("HEAD", path, nil))
+    if got := rec.Result().Header.Get("Server-Timing"); !pattern.MatchString(got) {
+      t.Errorf("HEAD %s: got Server-Timing %q", path, got)
+    }
+  }
+}
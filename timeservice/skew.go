@@ -0,0 +1,32 @@
+package timeservice
+
+import (
+  "net/http"
+  "time"
+)
+
+type SkewResult struct {
+  ServerTime time.Time `json:"serverTime"`
+  ClientTime time.Time `json:"clientTime"`
+  SkewMillis float64 `json:"skewMillis"`
+}
+
+// SkewHandler compares the client's clock, sent as client_time, against
+// the server's. SkewMillis is positive when the client is ahead.
+func SkewHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    clientTime, err := queryTime(r, "client_time")
+    if err != nil {
+      writeError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    now := cfg.Clock.Now()
+    writeJSON(w, r, SkewResult{
+      ServerTime: now,
+      ClientTime: clientTime,
+      SkewMillis: float64(clientTime.Sub(now)) / float64(time.Millisecond),
+    })
+  }
+}
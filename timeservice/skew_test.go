@@ -0,0 +1,45 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "net/url"
+  "testing"
+  "time"
+)
+
+func TestSkew(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  tests := []struct {
+    clientTime string
+    status int
+    skew float64
+  }{
+    {"2016-09-23T10:39:01.5Z", http.StatusOK, 1500},
+    {"2016-09-23T11:38:59.75+01:00", http.StatusOK, -250},
+    {"2016-09-23T10:39:00Z", http.StatusOK, 0},
+    {"", http.StatusBadRequest, 0},
+    {"23 Sep 16 10:39 +0000", http.StatusBadRequest, 0},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    SkewHandler(cfg)(rec, httptest.NewRequest("GET", "/v1/skew?client_time="+url.QueryEscape(tt.clientTime), nil))
+    if rec.Code != tt.status {
+      t.Errorf("%q: got status %d, want %d", tt.clientTime, rec.Code, tt.status)
+      continue
+    }
+    if tt.status != http.StatusOK {
+      continue
+    }
+    var got SkewResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+      t.Errorf("%q: %v", tt.clientTime, err)
+      continue
+    }
+    if got.SkewMillis != tt.skew || !got.ServerTime.Equal(cfg.Clock.Now()) {
+      t.Errorf("%q: got %+v, want skew %v", tt.clientTime, got, tt.skew)
+    }
+  }
+}
@@ -0,0 +1,34 @@
+package timeservice
+
+import (
+  "fmt"
+  "net/http"
+  "strconv"
+  "time"
+)
+
+const maxSleep = 30 * time.Second
+
+type SleepResult struct {
+  Millis int64 `json:"millis"`
+}
+
+// SleepHandler waits for ms milliseconds before answering, for exercising
+// timeouts and retries. It gives up with 503 Service Unavailable as soon
+// as the request's context is done.
+func SleepHandler(w http.ResponseWriter, r *http.Request) {
+  ms, err := strconv.ParseInt(r.URL.Query().Get("ms"), 10, 64)
+  if err != nil || ms < 0 || ms > maxSleep.Milliseconds() {
+    writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid ms: must be an integer from 0 to %d", maxSleep.Milliseconds()))
+    return
+  }
+
+  timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+  defer timer.Stop()
+  select {
+  case <-timer.C:
+    writeJSON(w, r, SleepResult{ms})
+  case <-r.Context().Done():
+    writeError(w, http.StatusServiceUnavailable, "request cancelled: "+r.Context().Err().Error())
+  }
+}
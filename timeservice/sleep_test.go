@@ -0,0 +1,54 @@
+package timeservice
+
+import (
+  "context"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestSleepHandler(t *testing.T) {
+  tests := []struct {
+    query string
+    status int
+  }{
+    {"ms=5", http.StatusOK},
+    {"ms=0", http.StatusOK},
+    {"", http.StatusBadRequest},
+    {"ms=-1", http.StatusBadRequest},
+    {"ms=soon", http.StatusBadRequest},
+    {"ms=30001", http.StatusBadRequest},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    SleepHandler(rec, httptest.NewRequest("GET", "/sleep?"+tt.query, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%q: got status %d, want %d", tt.query, rec.Code, tt.status)
+    }
+  }
+
+  rec := httptest.NewRecorder()
+  SleepHandler(rec, httptest.NewRequest("GET", "/sleep?ms=5", nil))
+  var got SleepResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Millis != 5 {
+    t.Errorf("got body %q", rec.Body.String())
+  }
+}
+
+func TestSleepHandlerCancelled(t *testing.T) {
+  ctx, cancel := context.WithCancel(context.Background())
+  time.AfterFunc(10*time.Millisecond, cancel)
+  req := httptest.NewRequest("GET", "/sleep?ms=30000", nil).WithContext(ctx)
+
+  start := time.Now()
+  rec := httptest.NewRecorder()
+  SleepHandler(rec, req)
+  if elapsed := time.Since(start); elapsed > 5*time.Second {
+    t.Errorf("returned after %v, expected to stop at cancellation", elapsed)
+  }
+  if rec.Code != http.StatusServiceUnavailable {
+    t.Errorf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+  }
+}
@@ -0,0 +1,61 @@
+package timeservice
+
+import (
+  "sync"
+  "sync/atomic"
+)
+
+// State is the service's runtime state, shared between the handlers and
+// the process that owns them. It is safe for concurrent use, and its
+// zero value is ready to use.
+type State struct {
+  maintenance atomic.Bool
+  ready atomic.Bool
+  draining atomic.Bool
+
+  drainOnce sync.Once
+  drainInit sync.Once
+  drained chan struct{}
+}
+
+func (s *State) SetMaintenance(on bool) {
+  s.maintenance.Store(on)
+}
+
+func (s *State) InMaintenance() bool {
+  return s.maintenance.Load()
+}
+
+// SetReady marks startup as finished (or not), which is what /readyz
+// reports.
+func (s *State) SetReady(ready bool) {
+  s.ready.Store(ready)
+}
+
+// Ready reports whether the service should receive traffic: startup has
+// finished and no drain has begun.
+func (s *State) Ready() bool {
+  return s.ready.Load() && !s.draining.Load()
+}
+
+// Drain takes the service out of rotation for good and notifies whoever
+// waits on Draining. Calling it again has no further effect.
+func (s *State) Drain() {
+  s.draining.Store(true)
+  s.drainOnce.Do(func() { close(s.drainChan()) })
+}
+
+// IsDraining reports whether Drain has been called.
+func (s *State) IsDraining() bool {
+  return s.draining.Load()
+}
+
+// Draining returns a channel that is closed once Drain is called.
+func (s *State) Draining() <-chan struct{} {
+  return s.drainChan()
+}
+
+func (s *State) drainChan() chan struct{} {
+  s.drainInit.Do(func() { s.drained = make(chan struct{}) })
+  return s.drained
+}
@@ -0,0 +1,110 @@
+package timeservice
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net/http"
+  "time"
+)
+
+const (
+  statusCheckTimeout = 2 * time.Second
+  // maxClockOffset is how far an NTP-backed clock may have to correct the
+  // system clock before the host's time is considered unhealthy.
+  maxClockOffset = time.Second
+)
+
+// Check is one component of the /status report.
+type Check interface {
+  Name() string
+  Run(ctx context.Context) error
+}
+
+// checkFunc adapts a function to Check.
+type checkFunc struct {
+  name string
+  run func(ctx context.Context) error
+}
+
+func (c checkFunc) Name() string { return c.name }
+func (c checkFunc) Run(ctx context.Context) error { return c.run(ctx) }
+
+// DefaultChecks are the checks /status runs: the clock tells the time,
+// named zones load, an NTP correction is within maxClockOffset, and
+// uptime is not negative.
+func DefaultChecks(cfg Config) []Check {
+  cfg = cfg.withDefaults()
+  return []Check{
+    checkFunc{"clock", func(context.Context) error {
+      if cfg.Clock.Now().IsZero() {
+        return errors.New("clock returned the zero time")
+      }
+      return nil
+    }},
+    checkFunc{"tzdata", func(context.Context) error {
+      if !TZDataAvailable() {
+        return errNoTZData
+      }
+      return nil
+    }},
+    checkFunc{"ntp_offset", func(context.Context) error {
+      oc, ok := cfg.Clock.(offsetClock)
+      if !ok {
+        return nil
+      }
+      if offset := oc.Offset(); offset.Abs() > maxClockOffset {
+        return fmt.Errorf("system clock is off by %v, more than %v", offset, maxClockOffset)
+      }
+      return nil
+    }},
+    checkFunc{"uptime", func(context.Context) error {
+      if uptime := cfg.Clock.Now().Sub(cfg.StartTime); uptime < 0 {
+        return fmt.Errorf("uptime is negative (%v): the clock went backwards", uptime)
+      }
+      return nil
+    }},
+  }
+}
+
+type CheckResult struct {
+  Name string `json:"name"`
+  Status string `json:"status"`
+  Message string `json:"message,omitempty"`
+}
+
+type StatusResult struct {
+  Healthy bool `json:"healthy"`
+  Checks []CheckResult `json:"checks"`
+}
+
+// StatusHandler runs checks, each bounded by statusCheckTimeout, and
+// reports them individually along with the overall result: 200 when all
+// pass, 503 Service Unavailable otherwise.
+func StatusHandler(checks ...Check) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    result := StatusResult{Healthy: true, Checks: make([]CheckResult, len(checks))}
+    for i, c := range checks {
+      ctx, cancel := context.WithTimeout(r.Context(), statusCheckTimeout)
+      err := c.Run(ctx)
+      cancel()
+      result.Checks[i] = CheckResult{Name: c.Name(), Status: "pass"}
+      if err != nil {
+        result.Healthy = false
+        result.Checks[i].Status = "fail"
+        result.Checks[i].Message = err.Error()
+      }
+    }
+
+    js, err := marshalJSON(r, result)
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+    w.Header().Set("Content-Type", contentTypeJSON)
+    if !result.Healthy {
+      w.WriteHeader(http.StatusServiceUnavailable)
+    }
+    w.Write(js)
+  }
+}
@@ -0,0 +1,76 @@
+package timeservice
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+type offsetFixedClock struct {
+  fixedClock
+  offset time.Duration
+}
+
+func (c offsetFixedClock) Offset() time.Duration { return c.offset }
+
+func getStatus(t *testing.T, h http.Handler) (int, StatusResult) {
+  t.Helper()
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+  var result StatusResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+    t.Fatal(err)
+  }
+  return rec.Code, result
+}
+
+func TestStatus(t *testing.T) {
+  if !TZDataAvailable() {
+    t.Skip("no time zone database on this host")
+  }
+  code, result := getStatus(t, NewHandler(testConfig(t)))
+  if code != http.StatusOK || !result.Healthy {
+    t.Fatalf("got status %d, %+v", code, result)
+  }
+  want := []string{"clock", "tzdata", "ntp_offset", "uptime"}
+  if len(result.Checks) != len(want) {
+    t.Fatalf("got checks %+v", result.Checks)
+  }
+  for i, name := range want {
+    if c := result.Checks[i]; c.Name != name || c.Status != "pass" || c.Message != "" {
+      t.Errorf("check %d: got %+v, want %s passing", i, c, name)
+    }
+  }
+}
+
+func TestStatusFailing(t *testing.T) {
+  cfg := testConfig(t)
+  now := time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC)
+  cfg.Clock = offsetFixedClock{fixedClock(now), 3 * time.Second}
+  cfg.StartTime = now
+  code, result := getStatus(t, StatusHandler(DefaultChecks(cfg)...))
+  if code != http.StatusServiceUnavailable || result.Healthy {
+    t.Errorf("got status %d, healthy %v", code, result.Healthy)
+  }
+  for _, c := range result.Checks {
+    failing := c.Name == "ntp_offset"
+    if (c.Status == "fail") != failing || (c.Message != "") != failing {
+      t.Errorf("got %+v", c)
+    }
+  }
+
+  custom := checkFunc{"custom", func(ctx context.Context) error {
+    if _, ok := ctx.Deadline(); !ok {
+      return errors.New("no deadline")
+    }
+    return nil
+  }}
+  code, result = getStatus(t, StatusHandler(custom))
+  if code != http.StatusOK || !result.Healthy || result.Checks[0].Status != "pass" {
+    t.Errorf("custom check: got status %d, %+v", code, result)
+  }
+}
@@ -0,0 +1,65 @@
+package timeservice
+
+import (
+  "fmt"
+  "net/http"
+  "time"
+)
+
+const streamInterval = time.Second
+
+// StreamHandler serves a Server-Sent Event stream of the time, one event
+// per second.
+func StreamHandler(cfg Config) http.HandlerFunc {
+  return streamHandler(cfg.withDefaults(), streamInterval)
+}
+
+// streamHandler pushes a ServiceResult as a Server-Sent Event every
+// interval until the client goes away. It honors the same format and tz
+// parameters as the root handler.
+func streamHandler(cfg Config, interval time.Duration) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    loc, err := requestLocation(r)
+    if err != nil {
+      writeLocationError(w, err)
+      return
+    }
+    format := requestFormat(r)
+    if _, ok := timeFormats[format]; !ok {
+      writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q", format))
+      return
+    }
+
+    rc := http.NewResponseController(w)
+    // The stream outlives the server's WriteTimeout by design.
+    rc.SetWriteDeadline(time.Time{})
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+      sr, err := cfg.serviceResult(r, format, loc)
+      if err != nil {
+        return
+      }
+      js, err := marshalNamed(r, sr)
+      if err != nil {
+        return
+      }
+      if _, err := fmt.Fprintf(w, "data: %s\n\n", js); err != nil {
+        return
+      }
+      if err := rc.Flush(); err != nil {
+        return
+      }
+
+      select {
+      case <-r.Context().Done():
+        return
+      case <-ticker.C:
+      }
+    }
+  }
+}
@@ -0,0 +1,60 @@
+package timeservice
+
+import (
+  "bufio"
+  "context"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestStream(t *testing.T) {
+  done := make(chan struct{})
+  h := streamHandler(testConfig(t), 10*time.Millisecond)
+  srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    defer close(done)
+    h(w, r)
+  }))
+  defer srv.Close()
+
+  ctx, cancel := context.WithCancel(context.Background())
+  defer cancel()
+  req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/stream?format=rfc3339", nil)
+  resp, err := http.DefaultClient.Do(req)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer resp.Body.Close()
+  if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+    t.Fatalf("got Content-Type %q", ct)
+  }
+
+  scanner := bufio.NewScanner(resp.Body)
+  for events := 0; events < 2; {
+    if !scanner.Scan() {
+      t.Fatalf("stream ended after %d events: %v", events, scanner.Err())
+    }
+    data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+    if !ok {
+      continue
+    }
+    var sr ServiceResult
+    if err := json.Unmarshal([]byte(data), &sr); err != nil {
+      t.Fatalf("event %q: %v", data, err)
+    }
+    if _, err := time.Parse(time.RFC3339, sr.FormattedTime); err != nil {
+      t.Errorf("event %q: %v", data, err)
+    }
+    events++
+  }
+
+  cancel()
+  select {
+  case <-done:
+  case <-time.After(time.Second):
+    t.Fatal("handler did not return after the client disconnected")
+  }
+}
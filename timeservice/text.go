@@ -0,0 +1,30 @@
+package timeservice
+
+import (
+  "net/http"
+)
+
+// TextHandler serves just the formatted time and a newline as plain
+// text, for shell scripts. It honors the same format and tz parameters
+// as TimeHandler.
+func TextHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    loc, err := requestLocation(r)
+    if err != nil {
+      writeLocationError(w, err)
+      return
+    }
+    t, err := cfg.currentTime(requestFormat(r), loc)
+    if err != nil {
+      writeError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    w.Header().Set("Content-Type", contentTypeText)
+    if r.Method == http.MethodHead {
+      return
+    }
+    w.Write([]byte(t + "\n"))
+  }
+}
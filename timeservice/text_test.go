@@ -0,0 +1,31 @@
+package timeservice
+
+import (
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestTextHandler(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  h := NewHandler(cfg)
+  tests := []struct {
+    path string
+    body string
+  }{
+    {"/time.txt", "23 Sep 16 10:39 +0000\n"},
+    {"/v1/time.txt?format=unix", "1474627140\n"},
+    {"/time.txt?format=rfc3339&tz=Europe/Lisbon", "2016-09-23T11:39:00+01:00\n"},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+    if got := rec.Body.String(); got != tt.body {
+      t.Errorf("%s: got body %q, want %q", tt.path, got, tt.body)
+    }
+    if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+      t.Errorf("%s: got Content-Type %q", tt.path, got)
+    }
+  }
+}
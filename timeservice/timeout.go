@@ -0,0 +1,52 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "time"
+)
+
+// handlerTimeoutBody is what a request that outlives Config.HandlerTimeout
+// gets, in the same shape as every other error.
+var handlerTimeoutBody, _ = json.Marshal(ErrorResult{"handler timed out", http.StatusServiceUnavailable})
+
+// boundedMux registers handlers on a ServeMux with each one bounded by
+// timeout. Streaming handlers, which http.TimeoutHandler would buffer,
+// must be registered on the embedded ServeMux directly.
+type boundedMux struct {
+  *http.ServeMux
+  timeout time.Duration
+}
+
+func (m boundedMux) Handle(pattern string, h http.Handler) {
+  m.ServeMux.Handle(pattern, withTimeout(m.timeout, h))
+}
+
+func (m boundedMux) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+  m.Handle(pattern, http.HandlerFunc(h))
+}
+
+// withTimeout answers 503 Service Unavailable with handlerTimeoutBody if
+// h hasn't finished within d. A zero d leaves h unbounded.
+func withTimeout(d time.Duration, h http.Handler) http.Handler {
+  if d <= 0 {
+    return h
+  }
+  th := http.TimeoutHandler(h, d, string(handlerTimeoutBody))
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    th.ServeHTTP(&timeoutWriter{ResponseWriter: w}, r)
+  })
+}
+
+// timeoutWriter labels http.TimeoutHandler's timeout response as JSON,
+// which TimeoutHandler itself leaves to content sniffing.
+type timeoutWriter struct {
+  http.ResponseWriter
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+  if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+    w.Header().Set("Content-Type", contentTypeJSON)
+  }
+  w.ResponseWriter.WriteHeader(status)
+}
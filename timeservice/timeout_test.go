@@ -0,0 +1,34 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.HandlerTimeout = 20 * time.Millisecond
+  h := NewHandler(cfg)
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/sleep?ms=5000", nil))
+  if rec.Code != http.StatusServiceUnavailable {
+    t.Errorf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+  }
+  if got := rec.Header().Get("Content-Type"); got != "application/json" {
+    t.Errorf("got Content-Type %q", got)
+  }
+  var er ErrorResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &er); err != nil || er.Status != http.StatusServiceUnavailable {
+    t.Errorf("got body %q", rec.Body.String())
+  }
+
+  rec = httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/now", nil))
+  if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+    t.Errorf("fast handler: got status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+  }
+}
@@ -0,0 +1,292 @@
+// Package timeservice implements the servertime HTTP handlers.
+package timeservice
+
+import (
+  "time"
+  "encoding/xml"
+  "errors"
+  "net/http"
+  "fmt"
+  "log/slog"
+  "strconv"
+  "strings"
+
+  "golang.org/x/time/rate"
+)
+
+type ServiceResult struct {
+  XMLName xml.Name `json:"-" xml:"serviceResult"`
+  FormattedTime string `json:"formattedTime" xml:"formattedTime"`
+  Greeting string `json:"greeting" xml:"greeting"`
+}
+
+type ErrorResult struct {
+  Error string `json:"error"`
+  Status int `json:"status"`
+}
+
+// withDefaults fills in what cfg leaves unset: a nil Clock falls back to
+// the system clock, a nil Logger to slog's default logger, a zero
+// StartTime to the current time and a nil State to a fresh State.
+func (cfg Config) withDefaults() Config {
+  if cfg.Clock == nil {
+    cfg.Clock = realClock{}
+  }
+  if cfg.StartTime.IsZero() {
+    cfg.StartTime = cfg.Clock.Now()
+  }
+  if cfg.State == nil {
+    cfg.State = &State{}
+  }
+  if cfg.Logger == nil {
+    cfg.Logger = slog.Default()
+  }
+  return cfg
+}
+
+// NewHandler returns the servertime routes configured from cfg, wrapped
+// in the service's middleware. Unset fields get the same defaults as in
+// NewMux.
+func NewHandler(cfg Config) http.Handler {
+  cfg = cfg.withDefaults()
+  mux := NewMux(cfg)
+
+  // Middleware is applied innermost first.
+  var h http.Handler = mux
+  if cfg.Chaos {
+    h = newChaos(cfg).middleware(h)
+  }
+  h = maintenanceMode(cfg.State, h)
+  h = requireAPIKey(cfg.APIKey, h)
+  if cfg.RateLimit > 0 {
+    h = newRateLimiter(rate.Limit(cfg.RateLimit), cfg.RateBurst).middleware(h)
+  }
+  h = limitBody(cfg.MaxBodyBytes, h)
+  h = cors(cfg.AllowedOrigins, h)
+  h = recoverPanics(cfg.Logger, h)
+  h = gzipResponses(h)
+  h = serverTiming(h)
+  h = instrument(h)
+  h = logRequests(cfg.Logger, h)
+  if cfg.JSONNaming == NamingSnakeCase {
+    h = withSnakeCase(h)
+  }
+  if cfg.Envelope {
+    h = withEnvelope(cfg.Clock, h)
+  }
+  h = withRequestID(h)
+  h = withClientIP(cfg.TrustProxy, h)
+  h = traceRequests(h)
+  return h
+}
+
+// NewMux routes each endpoint to its handler, without any middleware.
+// Unset fields of cfg get defaults as described on withDefaults; the
+// handlers are also exported so they can be mounted individually.
+func NewMux(cfg Config) *http.ServeMux {
+  cfg = cfg.withDefaults()
+  mux := boundedMux{http.NewServeMux(), cfg.HandlerTimeout}
+  cache := newResponseCache(cfg.CacheTTL)
+  registerV1(mux, cfg, cache)
+  // The bare root predates versioning and stays an alias of /v1/time.
+  // Being a catch-all pattern, it must 404 anything it doesn't route.
+  mux.Handle("/", readOnly(exactPath(TimeHandler(cfg))))
+  // Operational and diagnostic endpoints aren't part of the versioned
+  // API and are served at the root only.
+  mux.Handle("/healthz", readOnly(http.HandlerFunc(HealthzHandler)))
+  mux.Handle("/readyz", readOnly(ReadyzHandler(cfg)))
+  mux.Handle("/status", readOnly(StatusHandler(DefaultChecks(cfg)...)))
+  mux.Handle("/version", readOnly(cache.middleware(http.HandlerFunc(VersionHandler))))
+  mux.Handle("/sleep", readOnly(http.HandlerFunc(SleepHandler)))
+  mux.Handle("/echo", readOnly(http.HandlerFunc(EchoHandler)))
+  mux.Handle("/metrics", readOnly(MetricsHandler()))
+  mux.HandleFunc("/admin/maintenance", MaintenanceHandler(cfg))
+  mux.HandleFunc("/admin/drain", DrainHandler(cfg))
+  return mux.ServeMux
+}
+
+// registerV1 mounts version 1 of the API under /v1/, and each endpoint
+// also at the root, where the API was served before versioning, so older
+// clients keep working; /v1/time is at the root as / itself. Unknown
+// paths below the prefix are 404s rather than falling through to the
+// root. Endpoints that don't depend on the current time are served
+// through cache; streams are exempt from the handler timeout.
+func registerV1(mux boundedMux, cfg Config, cache *responseCache) {
+  handle := func(register func(string, http.Handler), path string, h http.Handler) {
+    register("/v1"+path, h)
+    if path != "/time" {
+      register(path, h)
+    }
+  }
+  handle(mux.Handle, "/time", readOnly(TimeHandler(cfg)))
+  handle(mux.Handle, "/time.txt", readOnly(TextHandler(cfg)))
+  handle(mux.Handle, "/now", readOnly(NowHandler(cfg)))
+  handle(mux.Handle, "/batch", readOnly(BatchHandler(cfg)))
+  handle(mux.Handle, "/convert", readOnly(cache.middleware(http.HandlerFunc(ConvertHandler))))
+  handle(mux.Handle, "/duration", readOnly(cache.middleware(http.HandlerFunc(DurationHandler))))
+  handle(mux.Handle, "/uptime", readOnly(UptimeHandler(cfg)))
+  handle(mux.Handle, "/skew", readOnly(SkewHandler(cfg)))
+  handle(mux.ServeMux.Handle, "/stream", readOnly(StreamHandler(cfg)))
+  handle(mux.ServeMux.Handle, "/ws", readOnly(WebSocketHandler(cfg)))
+  mux.HandleFunc("/v1/", notFoundHandler)
+}
+
+// exactPath 404s requests that reach h through a catch-all pattern
+// without matching it exactly.
+func exactPath(h http.Handler) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != r.Pattern {
+      notFoundHandler(w, r)
+      return
+    }
+    h.ServeHTTP(w, r)
+  }
+}
+
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+  writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+}
+
+const defaultFormat = "rfc822z"
+
+var timeFormats = map[string]func(time.Time) string{
+  "rfc822z": func(t time.Time) string { return t.Format(time.RFC822Z) },
+  "rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+  "unix": func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) },
+  "unixmilli": func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) },
+}
+
+func (cfg Config) currentTime(format string, loc *time.Location) (string, error) {
+  f, ok := timeFormats[format]
+  if !ok {
+    return "", fmt.Errorf("unknown format %q", format)
+  }
+  return f(cfg.Clock.Now().In(loc)), nil
+}
+
+// serviceResult builds the root response, greeting in the language the
+// request prefers.
+func (cfg Config) serviceResult(r *http.Request, format string, loc *time.Location) (ServiceResult, error) {
+  t, err := cfg.currentTime(format, loc)
+  if err != nil {
+    return ServiceResult{}, err
+  }
+  greeting := greetingFor(r.Header.Get("Accept-Language"), cfg.Greeting)
+  return ServiceResult{FormattedTime: t, Greeting: greeting}, nil
+}
+
+// requestFormat returns the format query parameter, or defaultFormat.
+func requestFormat(r *http.Request) string {
+  if format := r.URL.Query().Get("format"); format != "" {
+    return format
+  }
+  return defaultFormat
+}
+
+// requestLocation resolves the tz query parameter, defaulting to UTC so
+// results don't depend on the host's local zone.
+func requestLocation(r *http.Request) (*time.Location, error) {
+  tz := r.URL.Query().Get("tz")
+  if tz == "" {
+    return time.UTC, nil
+  }
+  loc, err := lookupLocation(tz)
+  if errors.Is(err, errNoTZData) {
+    return nil, err
+  }
+  if err != nil {
+    return nil, fmt.Errorf("unknown time zone %q", tz)
+  }
+  return loc, nil
+}
+
+// serviceResultTypes are the representations the root handler can
+// produce, in order of preference.
+var serviceResultTypes = []string{contentTypeJSON, contentTypeXML, contentTypeText}
+
+// TimeHandler serves the current time as a ServiceResult in the
+// negotiated content type.
+func TimeHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    contentType, ok := negotiateContentType(r.Header.Get("Accept"), serviceResultTypes)
+    if !ok {
+      writeError(w, http.StatusNotAcceptable, "supported types are "+strings.Join(serviceResultTypes, ", "))
+      return
+    }
+    loc, err := requestLocation(r)
+    if err != nil {
+      writeLocationError(w, err)
+      return
+    }
+    format := requestFormat(r)
+    annotateSpan(r, format, loc.String())
+    sr, err := cfg.serviceResult(r, format, loc)
+    if err != nil {
+      writeError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+
+    var body []byte
+    switch contentType {
+    case contentTypeXML:
+      body, err = xml.Marshal(sr)
+    case contentTypeText:
+      body = []byte(sr.FormattedTime)
+    default:
+      body, err = marshalJSON(r, sr)
+    }
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+    w.Header().Set("Content-Type", contentType)
+    if r.Method == http.MethodHead {
+      return
+    }
+    w.Write(body)
+  }
+}
+
+// probePaths are polled by orchestrators, which never authenticate and
+// must not be throttled.
+var probePaths = map[string]bool{
+  "/healthz": true,
+  "/readyz": true,
+}
+
+var healthzBody = []byte(`{"status":"ok"}`)
+
+// HealthzHandler is the liveness probe: it passes whenever the process
+// can serve at all.
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+  w.Header().Set("Content-Type", contentTypeJSON)
+  w.Write(healthzBody)
+}
+
+var (
+  notReadyBody = []byte(`{"status":"starting"}`)
+  drainingBody = []byte(`{"status":"draining"}`)
+)
+
+// ReadyzHandler reports 503 Service Unavailable until cfg.State is marked
+// ready, so traffic is held back while startup finishes, and again for
+// good once a drain begins.
+func ReadyzHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", contentTypeJSON)
+    if cfg.State.IsDraining() {
+      w.WriteHeader(http.StatusServiceUnavailable)
+      w.Write(drainingBody)
+      return
+    }
+    if !cfg.State.Ready() {
+      w.WriteHeader(http.StatusServiceUnavailable)
+      w.Write(notReadyBody)
+      return
+    }
+    w.Write(healthzBody)
+  }
+}
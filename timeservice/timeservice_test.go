@@ -0,0 +1,383 @@
+package timeservice
+
+import (
+  "testing"
+  "time"
+  "strconv"
+  "strings"
+  "encoding/json"
+  "io"
+  "log/slog"
+  "net/http"
+  "net/http/httptest"
+  "os"
+)
+
+// configEnv lists the variables LoadConfig reads.
+var configEnv = []string{
+  "PORT", "LISTEN_SOCKET", "TLS_CERT", "TLS_KEY", "GREETING", "NTP_SERVER",
+  "LOG_LEVEL", "GOOGLE_CLOUD_PROJECT", "HANDLER_TIMEOUT", "DRAIN_GRACE_PERIOD",
+  "READ_HEADER_TIMEOUT", "READ_TIMEOUT", "WRITE_TIMEOUT", "IDLE_TIMEOUT", "H2C",
+  "CACHE_TTL", "API_KEY", "RATE_LIMIT", "RATE_BURST", "MAX_BODY_BYTES",
+  "ALLOWED_ORIGINS", "TRUST_PROXY", "MAINTENANCE", "CHAOS", "CHAOS_MAX_DELAY",
+  "CHAOS_ERROR_PERCENT", "ENVELOPE", "JSON_NAMING", "OTEL_EXPORTER_OTLP_ENDPOINT",
+}
+
+// ambientEnv is the environment the tests were started in.
+var ambientEnv = func() map[string]string {
+  env := map[string]string{}
+  for _, name := range configEnv {
+    env[name] = os.Getenv(name)
+  }
+  return env
+}()
+
+// testConfig loads the configuration with logging discarded and with
+// none of the developer's environment, only what the test itself set.
+func testConfig(t *testing.T) Config {
+  for _, name := range configEnv {
+    if v := os.Getenv(name); v != "" && v == ambientEnv[name] {
+      t.Setenv(name, "")
+    }
+  }
+  cfg, err := LoadConfig()
+  if err != nil {
+    t.Fatal(err)
+  }
+  cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+  return cfg
+}
+
+func TestCurrentTime(t *testing.T) {
+  result, err := testConfig(t).currentTime(defaultFormat, time.UTC)
+  if err != nil {
+    t.Fatal(err)
+  }
+  // Check that err is null for RFC822Z time format
+  _, err = time.Parse(time.RFC822Z, result)
+  if err != nil {
+    t.Fail()
+  }
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+  return time.Time(c)
+}
+
+func TestHandlerFixedClock(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  tests := []struct {
+    query string
+    want string
+  }{
+    {"", "23 Sep 16 10:39 +0000"},
+    {"?format=rfc3339", "2016-09-23T10:39:00Z"},
+    {"?format=rfc3339&tz=Europe/Lisbon", "2016-09-23T11:39:00+01:00"},
+    {"?format=unix", "1474627140"},
+    {"?format=unixmilli", "1474627140000"},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    TimeHandler(cfg)(rec, httptest.NewRequest("GET", "/"+tt.query, nil))
+    var sr ServiceResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &sr); err != nil {
+      t.Errorf("%q: %v", tt.query, err)
+      continue
+    }
+    if sr.FormattedTime != tt.want {
+      t.Errorf("%q: got %q, want %q", tt.query, sr.FormattedTime, tt.want)
+    }
+  }
+}
+
+func TestHandlerFormats(t *testing.T) {
+  parseLayout := func(layout string) func(string) error {
+    return func(s string) error {
+      _, err := time.Parse(layout, s)
+      return err
+    }
+  }
+  parseInt := func(s string) error {
+    _, err := strconv.ParseInt(s, 10, 64)
+    return err
+  }
+  tests := []struct {
+    query string
+    parse func(string) error
+  }{
+    {"", parseLayout(time.RFC822Z)},
+    {"?format=rfc822z", parseLayout(time.RFC822Z)},
+    {"?format=rfc3339", parseLayout(time.RFC3339)},
+    {"?format=unix", parseInt},
+    {"?format=unixmilli", parseInt},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    TimeHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/"+tt.query, nil))
+    if rec.Code != http.StatusOK {
+      t.Errorf("%q: got status %d", tt.query, rec.Code)
+      continue
+    }
+    var sr ServiceResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &sr); err != nil {
+      t.Errorf("%q: %v", tt.query, err)
+      continue
+    }
+    if err := tt.parse(sr.FormattedTime); err != nil {
+      t.Errorf("%q: %q does not parse back: %v", tt.query, sr.FormattedTime, err)
+    }
+  }
+}
+
+func TestHandlerUnknownFormat(t *testing.T) {
+  rec := httptest.NewRecorder()
+  TimeHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/?format=iso", nil))
+  if rec.Code != http.StatusBadRequest {
+    t.Fatalf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+  }
+  var er ErrorResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &er); err != nil || er.Error == "" {
+    t.Errorf("expected a JSON error body, got %q", rec.Body.String())
+  }
+}
+
+func TestHealthz(t *testing.T) {
+  tests := []struct {
+    method string
+    status int
+    contentType string
+    body string
+  }{
+    {"GET", http.StatusOK, "application/json", `{"status":"ok"}`},
+    {"HEAD", http.StatusOK, "application/json", `{"status":"ok"}`},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    HealthzHandler(rec, httptest.NewRequest(tt.method, "/healthz", nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.method, rec.Code, tt.status)
+    }
+    if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+      t.Errorf("%s: got Content-Type %q, want %q", tt.method, ct, tt.contentType)
+    }
+    if rec.Body.String() != tt.body {
+      t.Errorf("%s: got body %q, want %q", tt.method, rec.Body.String(), tt.body)
+    }
+  }
+}
+
+func TestReadyz(t *testing.T) {
+  cfg := testConfig(t)
+  h := NewHandler(cfg)
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+  if rec.Code != http.StatusServiceUnavailable {
+    t.Errorf("before ready: got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+  }
+
+  cfg.State.SetReady(true)
+  rec = httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+  if rec.Code != http.StatusOK {
+    t.Errorf("after ready: got status %d, want %d", rec.Code, http.StatusOK)
+  }
+  if got := rec.Body.String(); got != `{"status":"ok"}` {
+    t.Errorf("after ready: got body %q", got)
+  }
+}
+
+func TestHandlerGreeting(t *testing.T) {
+  t.Setenv("GREETING", "Hello from staging")
+  rec := httptest.NewRecorder()
+  TimeHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/", nil))
+  var sr ServiceResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &sr); err != nil {
+    t.Fatal(err)
+  }
+  if sr.Greeting != "Hello from staging" {
+    t.Errorf("got greeting %q, want %q", sr.Greeting, "Hello from staging")
+  }
+}
+
+func TestHandlerTimeZone(t *testing.T) {
+  tests := []struct {
+    query string
+    status int
+    offset string
+  }{
+    {"?format=rfc3339", http.StatusOK, "Z"},
+    {"?format=rfc3339&tz=UTC", http.StatusOK, "Z"},
+    {"?format=rfc3339&tz=Asia/Kolkata", http.StatusOK, "+05:30"},
+    {"?tz=Mars/Olympus_Mons", http.StatusBadRequest, ""},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    TimeHandler(testConfig(t))(rec, httptest.NewRequest("GET", "/"+tt.query, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%q: got status %d, want %d", tt.query, rec.Code, tt.status)
+      continue
+    }
+    if tt.status != http.StatusOK {
+      continue
+    }
+    var sr ServiceResult
+    if err := json.Unmarshal(rec.Body.Bytes(), &sr); err != nil {
+      t.Errorf("%q: %v", tt.query, err)
+      continue
+    }
+    if !strings.HasSuffix(sr.FormattedTime, tt.offset) {
+      t.Errorf("%q: got %q, want offset %q", tt.query, sr.FormattedTime, tt.offset)
+    }
+  }
+}
+
+func TestHandlerContentNegotiation(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = fixedClock(time.Date(2016, time.September, 23, 10, 39, 0, 0, time.UTC))
+  tests := []struct {
+    accept string
+    status int
+    contentType string
+    body string
+  }{
+    {"", http.StatusOK, "application/json", `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {"application/json", http.StatusOK, "application/json", `{"formattedTime":"23 Sep 16 10:39 +0000","greeting":"Hi there"}`},
+    {"application/xml", http.StatusOK, "application/xml", `<serviceResult><formattedTime>23 Sep 16 10:39 +0000</formattedTime><greeting>Hi there</greeting></serviceResult>`},
+    {"text/plain", http.StatusOK, "text/plain", `23 Sep 16 10:39 +0000`},
+    {"text/html, application/xml;q=0.9, */*;q=0.8", http.StatusOK, "application/xml", ""},
+    {"text/*", http.StatusOK, "text/plain", ""},
+    {"image/png", http.StatusNotAcceptable, "application/json", ""},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest("GET", "/", nil)
+    req.Header.Set("Accept", tt.accept)
+    rec := httptest.NewRecorder()
+    TimeHandler(cfg)(rec, req)
+    if rec.Code != tt.status {
+      t.Errorf("Accept %q: got status %d, want %d", tt.accept, rec.Code, tt.status)
+    }
+    if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+      t.Errorf("Accept %q: got Content-Type %q, want %q", tt.accept, ct, tt.contentType)
+    }
+    if tt.body != "" && rec.Body.String() != tt.body {
+      t.Errorf("Accept %q: got body %q, want %q", tt.accept, rec.Body.String(), tt.body)
+    }
+  }
+}
+
+func TestVersion(t *testing.T) {
+  rec := httptest.NewRecorder()
+  VersionHandler(rec, httptest.NewRequest("GET", "/version", nil))
+  if rec.Code != http.StatusOK {
+    t.Fatalf("got status %d", rec.Code)
+  }
+  want := `{"version":"dev","commit":"unknown","buildTime":"unknown"}`
+  if rec.Body.String() != want {
+    t.Errorf("got body %q, want %q", rec.Body.String(), want)
+  }
+}
+
+func TestHandlerHead(t *testing.T) {
+  tests := []struct {
+    accept string
+    contentType string
+  }{
+    {"", "application/json"},
+    {"application/xml", "application/xml"},
+  }
+  for _, tt := range tests {
+    req := httptest.NewRequest("HEAD", "/", nil)
+    req.Header.Set("Accept", tt.accept)
+    rec := httptest.NewRecorder()
+    TimeHandler(testConfig(t))(rec, req)
+    if rec.Code != http.StatusOK {
+      t.Errorf("Accept %q: got status %d", tt.accept, rec.Code)
+    }
+    if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+      t.Errorf("Accept %q: got Content-Type %q, want %q", tt.accept, ct, tt.contentType)
+    }
+    if rec.Body.Len() != 0 {
+      t.Errorf("Accept %q: expected an empty body, got %q", tt.accept, rec.Body.String())
+    }
+  }
+}
+
+func TestRoutes(t *testing.T) {
+  h := NewHandler(testConfig(t))
+  tests := []struct {
+    path string
+    status int
+  }{
+    {"/", http.StatusOK},
+    {"/v1/time", http.StatusOK},
+    {"/v1/now", http.StatusOK},
+    {"/v1/batch?tz=UTC", http.StatusOK},
+    {"/v1/uptime", http.StatusOK},
+    {"/v1/duration?start=2016-09-23T10:00:00Z&end=2016-09-23T10:00:00Z", http.StatusOK},
+    {"/v1/convert?time=2016-09-23T10:00:00Z&from=UTC&to=UTC", http.StatusOK},
+    {"/v1/unknown", http.StatusNotFound},
+    {"/now", http.StatusOK},
+    {"/batch?tz=UTC", http.StatusOK},
+    {"/uptime", http.StatusOK},
+    {"/duration?start=2016-09-23T10:00:00Z&end=2016-09-23T10:00:00Z", http.StatusOK},
+    {"/convert?time=2016-09-23T10:00:00Z&from=UTC&to=UTC", http.StatusOK},
+    {"/stream?format=iso", http.StatusBadRequest},
+    {"/ws?format=iso", http.StatusBadRequest},
+    {"/skew?client_time=2016-09-23T10:00:00Z", http.StatusOK},
+    {"/time.txt", http.StatusOK},
+    {"/unknown", http.StatusNotFound},
+    {"/healthz", http.StatusOK},
+    {"/version", http.StatusOK},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.path, rec.Code, tt.status)
+    }
+  }
+}
+
+func TestExportedHandlerDefaults(t *testing.T) {
+  srv := httptest.NewServer(NowHandler(Config{}))
+  defer srv.Close()
+  resp, err := http.Get(srv.URL)
+  if err != nil {
+    t.Fatal(err)
+  }
+  resp.Body.Close()
+  if resp.StatusCode != http.StatusOK {
+    t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+  }
+}
+
+func BenchmarkTimeHandler(b *testing.B) {
+  h := TimeHandler(Config{Greeting: "Hi there"})
+  req := httptest.NewRequest("GET", "/", nil)
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    h(httptest.NewRecorder(), req)
+  }
+}
+
+func BenchmarkNowHandler(b *testing.B) {
+  h := NowHandler(Config{})
+  req := httptest.NewRequest("GET", "/v1/now?tz=Europe/Lisbon", nil)
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    h(httptest.NewRecorder(), req)
+  }
+}
+
+func BenchmarkNewMux(b *testing.B) {
+  mux := NewMux(Config{Greeting: "Hi there"})
+  req := httptest.NewRequest("GET", "/v1/time", nil)
+  b.ReportAllocs()
+  for i := 0; i < b.N; i++ {
+    mux.ServeHTTP(httptest.NewRecorder(), req)
+  }
+}
@@ -0,0 +1,71 @@
+package timeservice
+
+import (
+  "context"
+  "net/http"
+
+  "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+  "go.opentelemetry.io/otel"
+  "go.opentelemetry.io/otel/attribute"
+  "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+  "go.opentelemetry.io/otel/propagation"
+  sdkresource "go.opentelemetry.io/otel/sdk/resource"
+  sdktrace "go.opentelemetry.io/otel/sdk/trace"
+  semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+  "go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "servertime"
+
+// SetupTracing installs the global propagator and, when
+// cfg.OTLPEndpoint is set, a tracer provider exporting spans over OTLP.
+// Otherwise the global provider stays a no-op. The returned function
+// flushes pending spans and should be called on shutdown.
+func SetupTracing(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+  otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+  if cfg.OTLPEndpoint == "" {
+    return func(context.Context) error { return nil }, nil
+  }
+
+  // The exporter reads OTEL_EXPORTER_OTLP_ENDPOINT and the related
+  // variables itself.
+  exporter, err := otlptracehttp.New(ctx)
+  if err != nil {
+    return nil, err
+  }
+  tp := sdktrace.NewTracerProvider(
+    sdktrace.WithBatcher(exporter),
+    sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+  )
+  otel.SetTracerProvider(tp)
+  return tp.Shutdown, nil
+}
+
+// traceRequests starts a server span per request, continuing any trace
+// propagated in the incoming headers. The span is named by method alone
+// until nameSpan adds the matched route; raw paths would give every
+// query and probe its own span name.
+func traceRequests(next http.Handler) http.Handler {
+  return otelhttp.NewHandler(next, serviceName, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+    return r.Method
+  }))
+}
+
+// nameSpan names the request span after the route r matched, once
+// routing has set r.Pattern.
+func nameSpan(r *http.Request) {
+  if r.Pattern == "" {
+    return
+  }
+  span := trace.SpanFromContext(r.Context())
+  span.SetName(r.Method + " " + r.Pattern)
+  span.SetAttributes(semconv.HTTPRoute(r.Pattern))
+}
+
+// annotateSpan records the resolved time parameters on the request span.
+func annotateSpan(r *http.Request, format, tz string) {
+  trace.SpanFromContext(r.Context()).SetAttributes(
+    attribute.String("servertime.format", format),
+    attribute.String("servertime.timezone", tz),
+  )
+}
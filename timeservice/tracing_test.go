@@ -0,0 +1,72 @@
+package timeservice
+
+import (
+  "net/http/httptest"
+  "testing"
+
+  "go.opentelemetry.io/otel"
+  "go.opentelemetry.io/otel/attribute"
+  "go.opentelemetry.io/otel/propagation"
+  sdktrace "go.opentelemetry.io/otel/sdk/trace"
+  "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceRequests(t *testing.T) {
+  recorder := tracetest.NewSpanRecorder()
+  tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+  prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+  otel.SetTracerProvider(tp)
+  otel.SetTextMapPropagator(propagation.TraceContext{})
+  defer func() {
+    otel.SetTracerProvider(prevTP)
+    otel.SetTextMapPropagator(prevProp)
+  }()
+
+  req := httptest.NewRequest("GET", "/?format=rfc3339&tz=Asia/Tokyo", nil)
+  req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+  NewHandler(testConfig(t)).ServeHTTP(httptest.NewRecorder(), req)
+
+  spans := recorder.Ended()
+  if len(spans) != 1 {
+    t.Fatalf("got %d spans, want 1", len(spans))
+  }
+  span := spans[0]
+  if got := span.Name(); got != "GET /" {
+    t.Errorf("got span name %q, want %q", got, "GET /")
+  }
+  if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+    t.Errorf("trace context not propagated, got trace ID %s", got)
+  }
+  if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+    t.Errorf("got parent span %s", got)
+  }
+  want := map[attribute.Key]string{
+    "servertime.format": "rfc3339",
+    "servertime.timezone": "Asia/Tokyo",
+  }
+  for _, kv := range span.Attributes() {
+    if v, ok := want[kv.Key]; ok {
+      if kv.Value.AsString() != v {
+        t.Errorf("%s = %q, want %q", kv.Key, kv.Value.AsString(), v)
+      }
+      delete(want, kv.Key)
+    }
+  }
+  if len(want) != 0 {
+    t.Errorf("missing span attributes %v", want)
+  }
+
+  // Parameters and unknown paths don't make new span names.
+  for _, path := range []string{"/v1/convert?time=2016-09-23T10:00:00Z&from=UTC&to=UTC", "/v1/no-such-path"} {
+    NewHandler(testConfig(t)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+  }
+  spans = recorder.Ended()
+  if len(spans) != 3 {
+    t.Fatalf("got %d spans, want 3", len(spans))
+  }
+  for i, want := range []string{"GET /v1/convert", "GET /v1/"} {
+    if got := spans[1+i].Name(); got != want {
+      t.Errorf("got span name %q, want %q", got, want)
+    }
+  }
+}
@@ -0,0 +1,44 @@
+package timeservice
+
+import (
+  "errors"
+  "net/http"
+  "time"
+)
+
+// probeZone is loaded to tell a missing time zone database apart from a
+// misspelled zone name.
+const probeZone = "America/New_York"
+
+var errNoTZData = errors.New("time zone database unavailable on this server")
+
+// loadLocation is time.LoadLocation, replaceable in tests.
+var loadLocation = time.LoadLocation
+
+// TZDataAvailable reports whether named zones can be loaded. It fails on
+// minimal images without tzdata, unless the binary embeds the database
+// by building with -tags timetzdata.
+func TZDataAvailable() bool {
+  _, err := loadLocation(probeZone)
+  return err == nil
+}
+
+// lookupLocation loads the named zone, returning errNoTZData when it
+// fails only because there is no database to load it from.
+func lookupLocation(name string) (*time.Location, error) {
+  loc, err := loadLocation(name)
+  if err != nil && !TZDataAvailable() {
+    return nil, errNoTZData
+  }
+  return loc, err
+}
+
+// writeLocationError reports a bad zone parameter with 400 Bad Request,
+// or with 501 Not Implemented when the server can't load zones at all.
+func writeLocationError(w http.ResponseWriter, err error) {
+  if errors.Is(err, errNoTZData) {
+    writeError(w, http.StatusNotImplemented, err.Error())
+    return
+  }
+  writeError(w, http.StatusBadRequest, err.Error())
+}
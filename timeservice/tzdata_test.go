@@ -0,0 +1,65 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+// withoutTZData simulates a host with no time zone database for the
+// rest of the test.
+func withoutTZData(t *testing.T) {
+  orig := loadLocation
+  loadLocation = func(name string) (*time.Location, error) {
+    if name == "" || name == "UTC" {
+      return time.UTC, nil
+    }
+    return nil, errors.New("unknown time zone " + name)
+  }
+  t.Cleanup(func() { loadLocation = orig })
+}
+
+func TestTZDataAvailable(t *testing.T) {
+  if !TZDataAvailable() {
+    t.Skip("no time zone database on this host")
+  }
+  withoutTZData(t)
+  if TZDataAvailable() {
+    t.Error("expected the database to be reported missing")
+  }
+}
+
+func TestMissingTZData(t *testing.T) {
+  withoutTZData(t)
+  h := NewHandler(testConfig(t))
+  tests := []struct {
+    path string
+    status int
+  }{
+    {"/?tz=Europe/Lisbon", http.StatusNotImplemented},
+    {"/v1/now?tz=Europe/Lisbon", http.StatusNotImplemented},
+    {"/v1/convert?time=2016-09-23T10:00:00Z&from=UTC&to=Asia/Tokyo", http.StatusNotImplemented},
+    {"/", http.StatusOK},
+    {"/?tz=UTC", http.StatusOK},
+  }
+  for _, tt := range tests {
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+    if rec.Code != tt.status {
+      t.Errorf("%s: got status %d, want %d", tt.path, rec.Code, tt.status)
+    }
+  }
+
+  rec := httptest.NewRecorder()
+  h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/batch?tz=UTC&tz=Europe/Lisbon", nil))
+  var entries []BatchEntry
+  if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+    t.Fatal(err)
+  }
+  if len(entries) != 2 || entries[0].Error != "" || entries[1].Error != errNoTZData.Error() {
+    t.Errorf("batch: got %s", rec.Body.String())
+  }
+}
@@ -0,0 +1,31 @@
+package timeservice
+
+import (
+  "net/http"
+  "time"
+)
+
+type UptimeResult struct {
+  StartTime time.Time `json:"startTime"`
+  CurrentTime time.Time `json:"currentTime"`
+  UptimeSeconds float64 `json:"uptimeSeconds"`
+  Uptime string `json:"uptime"`
+}
+
+// UptimeHandler reports how long the process has been running. With the
+// real clock both readings carry monotonic time, so the duration is
+// unaffected by wall clock adjustments.
+func UptimeHandler(cfg Config) http.HandlerFunc {
+  cfg = cfg.withDefaults()
+  return func(w http.ResponseWriter, r *http.Request) {
+    setLiveHeaders(w, cfg.Clock)
+    now := cfg.Clock.Now()
+    uptime := now.Sub(cfg.StartTime)
+    writeJSON(w, r, UptimeResult{
+      StartTime: cfg.StartTime,
+      CurrentTime: now,
+      UptimeSeconds: uptime.Seconds(),
+      Uptime: uptime.Round(time.Second).String(),
+    })
+  }
+}
@@ -0,0 +1,60 @@
+package timeservice
+
+import (
+  "encoding/json"
+  "io"
+  "log/slog"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestUptime(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.StartTime = time.Date(2016, time.September, 23, 10, 0, 0, 0, time.UTC)
+  cfg.Clock = fixedClock(cfg.StartTime.Add(26*time.Hour + 3*time.Minute + 4*time.Second))
+
+  rec := httptest.NewRecorder()
+  UptimeHandler(cfg)(rec, httptest.NewRequest("GET", "/v1/uptime", nil))
+  var got UptimeResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+    t.Fatal(err)
+  }
+  if !got.StartTime.Equal(cfg.StartTime) || !got.CurrentTime.Equal(cfg.Clock.Now()) {
+    t.Errorf("got start %v and current %v", got.StartTime, got.CurrentTime)
+  }
+  if got.UptimeSeconds != 93784 || got.Uptime != "26h3m4s" {
+    t.Errorf("got uptime %v (%q), want 93784 (26h3m4s)", got.UptimeSeconds, got.Uptime)
+  }
+}
+
+func TestUptimeDefaultsStartTime(t *testing.T) {
+  cfg := Config{
+    Clock: fixedClock(time.Date(2016, time.September, 23, 10, 0, 0, 0, time.UTC)),
+    Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+  }
+  rec := httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, httptest.NewRequest("GET", "/v1/uptime", nil))
+  var got UptimeResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+    t.Fatal(err)
+  }
+  if got.UptimeSeconds != 0 {
+    t.Errorf("got uptime %v, want 0", got.UptimeSeconds)
+  }
+}
+
+func TestUptimeNTPClock(t *testing.T) {
+  cfg := testConfig(t)
+  cfg.Clock = &NTPClock{offset: -time.Hour}
+  cfg.StartTime = cfg.Clock.Now()
+  rec := httptest.NewRecorder()
+  NewHandler(cfg).ServeHTTP(rec, httptest.NewRequest("GET", "/v1/uptime", nil))
+  var got UptimeResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+    t.Fatal(err)
+  }
+  if got.UptimeSeconds < 0 || got.UptimeSeconds > 60 {
+    t.Errorf("got uptime %v, want a few seconds at most", got.UptimeSeconds)
+  }
+}
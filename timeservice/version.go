@@ -0,0 +1,30 @@
+package timeservice
+
+import (
+  "net/http"
+)
+
+// Build metadata, injected at link time with
+//   -ldflags "-X servertime/timeservice.version=... -X servertime/timeservice.commit=..."
+var (
+  version = "dev"
+  commit = "unknown"
+  buildTime = "unknown"
+)
+
+type VersionResult struct {
+  Version string `json:"version"`
+  Commit string `json:"commit"`
+  BuildTime string `json:"buildTime"`
+}
+
+// VersionHandler reports the build's version, commit and build time.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+  js, err := marshalJSON(r, VersionResult{version, commit, buildTime})
+
+  if err != nil {
+    writeError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  writeCacheable(w, r, contentTypeJSON, js)
+}
@@ -0,0 +1,127 @@
+package timeservice
+
+import (
+  "bufio"
+  "bytes"
+  "context"
+  "fmt"
+  "net"
+  "net/http"
+  "strings"
+  "time"
+
+  "nhooyr.io/websocket"
+)
+
+const (
+  wsPingInterval = 30 * time.Second
+  wsPingTimeout = 10 * time.Second
+)
+
+// WebSocketHandler serves the time over a WebSocket, one message per
+// second.
+func WebSocketHandler(cfg Config) http.HandlerFunc {
+  return wsHandler(cfg.withDefaults(), streamInterval)
+}
+
+// wsHandler upgrades to a WebSocket and sends a ServiceResult every
+// interval until the client closes the connection or stops answering
+// pings. It honors the same format and tz parameters as the root handler.
+func wsHandler(cfg Config, interval time.Duration) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    loc, err := requestLocation(r)
+    if err != nil {
+      writeLocationError(w, err)
+      return
+    }
+    format := requestFormat(r)
+    if _, ok := timeFormats[format]; !ok {
+      writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q", format))
+      return
+    }
+
+    // The hijacked connection would otherwise keep the server timeouts.
+    rc := http.NewResponseController(w)
+    rc.SetReadDeadline(time.Time{})
+    rc.SetWriteDeadline(time.Time{})
+
+    aw := &acceptWriter{ResponseWriter: w}
+    c, err := websocket.Accept(aw, r, nil)
+    if err != nil {
+      // Accept wrote its error as plain text; send it as JSON instead.
+      status := aw.status
+      if status == 0 {
+        status = http.StatusBadRequest
+      }
+      writeError(w, status, strings.TrimSpace(aw.body.String()))
+      return
+    }
+    defer c.CloseNow()
+
+    // CloseRead answers pings and close frames from the client, and
+    // cancels ctx once the connection is closed.
+    ctx := c.CloseRead(r.Context())
+
+    send := func() error {
+      sr, err := cfg.serviceResult(r, format, loc)
+      if err != nil {
+        return err
+      }
+      js, err := marshalNamed(r, sr)
+      if err != nil {
+        return err
+      }
+      return c.Write(ctx, websocket.MessageText, js)
+    }
+    if err := send(); err != nil {
+      return
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    pings := time.NewTicker(wsPingInterval)
+    defer pings.Stop()
+    for {
+      select {
+      case <-ctx.Done():
+        return
+      case <-ticker.C:
+        if err := send(); err != nil {
+          return
+        }
+      case <-pings.C:
+        pingCtx, cancel := context.WithTimeout(ctx, wsPingTimeout)
+        err := c.Ping(pingCtx)
+        cancel()
+        if err != nil {
+          return
+        }
+      }
+    }
+  }
+}
+
+// acceptWriter holds back the error response websocket.Accept writes
+// when a handshake fails, so it can be sent in the usual JSON shape. The
+// 101 of a successful upgrade passes straight through.
+type acceptWriter struct {
+  http.ResponseWriter
+  status int
+  body bytes.Buffer
+}
+
+func (w *acceptWriter) WriteHeader(status int) {
+  if status == http.StatusSwitchingProtocols {
+    w.ResponseWriter.WriteHeader(status)
+    return
+  }
+  w.status = status
+}
+
+func (w *acceptWriter) Write(b []byte) (int, error) {
+  return w.body.Write(b)
+}
+
+func (w *acceptWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+  return http.NewResponseController(w.ResponseWriter).Hijack()
+}
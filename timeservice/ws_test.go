@@ -0,0 +1,72 @@
+package timeservice
+
+import (
+  "context"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+
+  "nhooyr.io/websocket"
+)
+
+func TestWebSocket(t *testing.T) {
+  done := make(chan struct{})
+  h := wsHandler(testConfig(t), 10*time.Millisecond)
+  // Serve through the full middleware stack to check that upgrades
+  // survive the response writer wrappers.
+  mux := http.NewServeMux()
+  mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+    defer close(done)
+    h(w, r)
+  })
+  srv := httptest.NewServer(logRequests(testConfig(t).Logger, instrument(gzipResponses(mux))))
+  defer srv.Close()
+
+  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+  c, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?format=unix", &websocket.DialOptions{
+    HTTPHeader: http.Header{"Accept-Encoding": {"gzip"}},
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer c.CloseNow()
+
+  for i := 0; i < 2; i++ {
+    typ, data, err := c.Read(ctx)
+    if err != nil {
+      t.Fatalf("message %d: %v", i, err)
+    }
+    var sr ServiceResult
+    if typ != websocket.MessageText || json.Unmarshal(data, &sr) != nil || sr.FormattedTime == "" {
+      t.Fatalf("message %d: unexpected %v %q", i, typ, data)
+    }
+  }
+
+  if err := c.Close(websocket.StatusNormalClosure, ""); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case <-done:
+  case <-time.After(time.Second):
+    t.Fatal("handler did not return after the client closed")
+  }
+}
+
+func TestWebSocketHandshakeError(t *testing.T) {
+  rec := httptest.NewRecorder()
+  NewHandler(testConfig(t)).ServeHTTP(rec, httptest.NewRequest("GET", "/v1/ws", nil))
+  var got ErrorResult
+  if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+    t.Fatalf("body %q is not an ErrorResult: %v", rec.Body, err)
+  }
+  if rec.Code != http.StatusUpgradeRequired || got.Status != rec.Code || got.Error == "" {
+    t.Errorf("got %d %+v, want %d with the error", rec.Code, got, http.StatusUpgradeRequired)
+  }
+  if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+    t.Errorf("got Content-Type %q", ct)
+  }
+}